@@ -0,0 +1,966 @@
+package bulkgen
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stoewer/go-strcase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
+)
+
+// renderBulk renders the bulk template with stubs for the funcs gqlgen normally supplies, so the
+// output can be asserted without a full codegen run. The reserved imports are returned keyed by path
+func renderBulk(t *testing.T, data BulkResolverBuild) (string, map[string]string) {
+	t.Helper()
+
+	imports := map[string]string{}
+
+	tmpl := template.New("bulk").Funcs(template.FuncMap{
+		"toLower":     strings.ToLower,
+		"toSnakeCase": strcase.SnakeCase,
+		"reserveImport": func(path string, aliases ...string) string {
+			imports[path] = strings.Join(aliases, "")
+
+			return ""
+		},
+	})
+
+	tmpl, err := tmpl.Parse(bulkTemplate)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, tmpl.Execute(&out, data))
+
+	return out.String(), imports
+}
+
+func TestBulkTemplateRuntimeImports(t *testing.T) {
+	data := BulkResolverBuild{
+		Objects: []Object{
+			{Name: "Task", PluralName: "Tasks", OperationType: "update"},
+		},
+		EntImport: "github.com/example/app/internal/ent/generated",
+	}
+
+	t.Run("default imports", func(t *testing.T) {
+		data.Imports = runtimeimports.Config{}.WithDefaults()
+
+		out, imports := renderBulk(t, data)
+
+		assert.Contains(t, out, "func (r *mutationResolver) bulkUpdateTask")
+		assert.Equal(t, "logx", imports[runtimeimports.DefaultLogger])
+		assert.Equal(t, "fgax", imports[runtimeimports.DefaultAuthz])
+		assert.Equal(t, "gqlerrors", imports[runtimeimports.DefaultGraphErrors])
+		assert.Equal(t, "rout", imports[runtimeimports.DefaultErrors])
+	})
+
+	t.Run("custom imports", func(t *testing.T) {
+		data.Imports = runtimeimports.Config{
+			Logger: "github.com/example/app/pkg/log",
+			Authz:  "github.com/example/app/pkg/authz",
+		}.WithDefaults()
+
+		_, imports := renderBulk(t, data)
+
+		assert.Equal(t, "logx", imports["github.com/example/app/pkg/log"])
+		assert.Equal(t, "fgax", imports["github.com/example/app/pkg/authz"])
+		assert.NotContains(t, imports, runtimeimports.DefaultLogger)
+		assert.NotContains(t, imports, runtimeimports.DefaultAuthz)
+	})
+}
+
+func TestBulkTemplateAtomic(t *testing.T) {
+	tests := []struct {
+		name   string
+		atomic bool
+	}{
+		{
+			name:   "best effort",
+			atomic: false,
+		},
+		{
+			name:   "atomic",
+			atomic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Task", PluralName: "Tasks", OperationType: "update", HasCSVUpdateMutation: true, Atomic: tt.atomic},
+					{Name: "Task", PluralName: "Tasks", OperationType: "delete", Atomic: tt.atomic},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+			}
+
+			out, imports := renderBulk(t, data)
+
+			assert.Contains(t, out, "func (r *mutationResolver) bulkUpdateTask")
+			assert.Contains(t, out, "func (r *mutationResolver) bulkUpdateCSVTask")
+			assert.Contains(t, out, "func (r *mutationResolver) bulkDeleteTask")
+
+			if !tt.atomic {
+				assert.NotContains(t, out, "graphutils.NewBulkError")
+				assert.Contains(t, out, "r.withPool().SubmitMultipleAndWait")
+
+				return
+			}
+
+			assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+			assert.Contains(t, out, `graphutils.NewBulkError("task", "update")`)
+			assert.Contains(t, out, `graphutils.NewBulkError("task", "delete")`)
+			assert.Contains(t, out, "return nil, bulkErr")
+			assert.Contains(t, out, "c.Task.DeleteOneID(id).Exec(ctx)")
+			assert.NotContains(t, out, "r.withPool().SubmitMultipleAndWait")
+			assert.NotContains(t, out, "gqlerrors.BulkActionIncomplete")
+		})
+	}
+}
+
+func TestBulkTemplateItemResults(t *testing.T) {
+	tests := []struct {
+		name        string
+		atomic      bool
+		itemResults bool
+	}{
+		{
+			name: "no item results",
+		},
+		{
+			name:        "item results",
+			itemResults: true,
+		},
+		{
+			name:        "atomic item results",
+			atomic:      true,
+			itemResults: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Task", PluralName: "Tasks", OperationType: "create"},
+					{Name: "Task", PluralName: "Tasks", OperationType: "update", HasCSVUpdateMutation: true, Atomic: tt.atomic},
+					{Name: "Task", PluralName: "Tasks", OperationType: "delete", Atomic: tt.atomic},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				ItemResults:        tt.itemResults,
+			}
+
+			out, imports := renderBulk(t, data)
+
+			// the generated code must be valid go
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			if !tt.itemResults {
+				assert.NotContains(t, out, "Results:")
+				assert.NotContains(t, out, "graphutils.NewBulkItem")
+				assert.NotContains(t, out, "graphutils.BulkItem")
+				assert.Contains(t, out, "c.Task.CreateBulk(builders...).Save(ctx)")
+
+				return
+			}
+
+			assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+			assert.Contains(t, out, "Results: itemResults,")
+			assert.Contains(t, out, "generated.IsValidationError(err)")
+			assert.Contains(t, out, "graphutils.NewBulkItemSuccess(i, input.ID)")
+			assert.NotContains(t, out, "c.Task.CreateBulk(builders...).Save(ctx)")
+
+			if tt.atomic {
+				assert.Contains(t, out, "graphutils.BulkItemSuccesses(ids)")
+				assert.Contains(t, out, "bulkErr.Add(i, id, rout.ErrPermissionDenied)")
+
+				return
+			}
+
+			assert.Contains(t, out, "graphutils.BulkItemResults(originalIDs, updated, failures, rout.ErrPermissionDenied)")
+			assert.Contains(t, out, "graphutils.BulkItemResults(originalIDs, deleted, failures, rout.ErrPermissionDenied)")
+			assert.Contains(t, out, "failures[i] = err")
+			assert.Contains(t, out, "for i, id := range originalIDs {")
+			assert.NotContains(t, out, "failures[id]")
+
+			// the rows of a bulk create that fail validation are reported in the payload
+			assert.Contains(t, out, "func isBulkRowError(err error) bool")
+			assert.Contains(t, out, `itemResults = append(itemResults, graphutils.NewBulkItemFailure(i, "", err))`)
+			assert.Contains(t, imports, "errors")
+		})
+	}
+}
+
+func TestBulkTemplateCreateChunks(t *testing.T) {
+	tests := []struct {
+		name         string
+		chunkSize    int
+		commitChunks bool
+		expected     []string
+		notExpected  []string
+	}{
+		{
+			name:        "single create bulk call",
+			expected:    []string{"c.Task.CreateBulk(builders...).Save(ctx)"},
+			notExpected: []string{"slices.Chunk", "bulkCreateTaskChunk"},
+		},
+		{
+			name:        "chunks in request transaction",
+			chunkSize:   500,
+			expected:    []string{"slices.Chunk(builders, 500)", "c.Task.CreateBulk(chunk...).Save(ctx)"},
+			notExpected: []string{"bulkCreateTaskChunk", "r.db.Tx(ctx)"},
+		},
+		{
+			name:         "chunks committed separately",
+			chunkSize:    1000,
+			commitChunks: true,
+			expected: []string{
+				"slices.Chunk(input, 1000)",
+				"func (r *mutationResolver) bulkCreateTaskChunk(",
+				"r.db.Tx(ctx)",
+				"tx.Commit()",
+			},
+			notExpected: []string{"withTransactionalMutation(ctx)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Task", PluralName: "Tasks", OperationType: "create"},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				CreateChunkSize:    tt.chunkSize,
+				CommitCreateChunks: tt.commitChunks,
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+
+			if tt.chunkSize > 0 {
+				assert.Contains(t, imports, "slices")
+			}
+		})
+	}
+}
+
+func TestBulkTemplateUpsert(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         *UpsertKey
+		edges       []UpsertEdge
+		csv         bool
+		itemResults bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name: "match by id",
+			expected: []string{
+				"c.Control.Get(ctx, row.ID)",
+				"updated, err := upsertControlUpdate(existing, row.Input).Save(ctx)",
+				"input.Mutate(update.Mutation())",
+				`matchedRows.Denied(r.filterAuthorizedIDs(ctx, matchedRows.IDs(), "control", fgax.CanEdit))`,
+				"bulkErr.Add(i, matches[i].ID, rout.ErrPermissionDenied)",
+			},
+			notExpected: []string{"Query().Where(", "ControlCSVUpsertInput", "update.Clear"},
+		},
+		{
+			// importing the same row twice replaces the edges of the control instead of adding the ids again
+			name:  "re-import replaces the edges",
+			edges: []UpsertEdge{{Name: "programIDs", GoName: "ProgramIDs", Clear: "ClearPrograms"}},
+			expected: []string{
+				"if len(input.ProgramIDs) > 0 {\n\t\tupdate.ClearPrograms()\n\t}\n\n\tinput.Mutate(update.Mutation())",
+			},
+		},
+		{
+			name: "nillable natural key",
+			key:  &UpsertKey{Name: "refCode", GoName: "RefCode", Nillable: true},
+			expected: []string{
+				"case row.Input.RefCode != nil:",
+				"c.Control.Query().Where(control.RefCodeEQ(*row.Input.RefCode)).Limit(2).All(ctx)",
+				`graphutils.NewAmbiguousUpsertKeyError("refCode", *row.Input.RefCode)`,
+			},
+		},
+		{
+			name:        "required natural key",
+			key:         &UpsertKey{Name: "displayID", GoName: "DisplayID"},
+			expected:    []string{"default:", "control.DisplayIDEQ(row.Input.DisplayID)"},
+			notExpected: []string{"*row.Input.DisplayID"},
+		},
+		{
+			name:     "csv upsert",
+			csv:      true,
+			expected: []string{"type ControlCSVUpsertInput struct {", "csvgenerated.ControlCSVInput"},
+		},
+		{
+			name:        "item results",
+			itemResults: true,
+			expected: []string{
+				`graphutils.NewBulkError("control", "upsert")`,
+				"Results:    itemResults,",
+				"if isBulkRowError(err) {",
+				"itemResults = append(itemResults, graphutils.NewBulkItemFailure(i, existing.ID, err))",
+				"itemResults = append(itemResults, graphutils.NewBulkItemFailure(i, matches[i].ID, rout.ErrPermissionDenied))",
+			},
+			notExpected: []string{"parseRequestError", "bulkErr.Add(i, matches[i].ID, rout.ErrPermissionDenied)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Control", PluralName: "Controls", OperationType: "upsert", HasCSVUpsertMutation: tt.csv, UpsertKey: tt.key, UpsertEdges: tt.edges},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				ItemResults:        tt.itemResults,
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			assert.Contains(t, out, "func (r *mutationResolver) bulkUpsertControl (ctx context.Context, rows []*graphutils.UpsertRow[generated.CreateControlInput])")
+			assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+
+			if tt.key != nil {
+				assert.Contains(t, imports, "github.com/example/app/internal/ent/generated/control")
+			}
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestBulkTemplateParallelUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		workers     int
+		atomic      bool
+		itemResults bool
+	}{
+		{
+			name: "serial",
+		},
+		{
+			name:    "atomic is serial",
+			workers: 8,
+			atomic:  true,
+		},
+		{
+			name:    "parallel",
+			workers: 8,
+		},
+		{
+			name:        "parallel with item results",
+			workers:     4,
+			itemResults: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Task", PluralName: "Tasks", OperationType: "update", HasCSVUpdateMutation: true, UpdateWorkers: tt.workers, Atomic: tt.atomic},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				ItemResults:        tt.itemResults,
+			}
+
+			out, _ := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			if tt.workers == 0 || tt.atomic {
+				assert.NotContains(t, out, "r.withPool().SubmitMultipleAndWait")
+				assert.NotContains(t, out, "sem := make(chan struct{}")
+				assert.Contains(t, out, "c.Task.Get(ctx, id)")
+				assert.Contains(t, out, "c.Task.Get(ctx, input.ID)")
+
+				return
+			}
+
+			assert.Equal(t, 2, strings.Count(out, "r.withPool().SubmitMultipleAndWait(funcs)"))
+			assert.Equal(t, 2, strings.Count(out, fmt.Sprintf("sem := make(chan struct{}, %d)", tt.workers)))
+			assert.Contains(t, out, "r.db.Task.Get(poolCtx, id)")
+			assert.Contains(t, out, "r.db.Task.Get(poolCtx, input.ID)")
+			assert.NotContains(t, out, "withTransactionalMutation(ctx)")
+
+			if tt.itemResults {
+				assert.Contains(t, out, "itemResults[i] = graphutils.NewBulkItemSuccess(i, input.ID)")
+				assert.Contains(t, out, "graphutils.BulkItemResults(originalIDs, updated, failures, rout.ErrPermissionDenied)")
+			}
+		})
+	}
+}
+
+func TestBulkTemplateSoftDelete(t *testing.T) {
+	tests := []struct {
+		name       string
+		softDelete bool
+		archivable bool
+		atomic     bool
+	}{
+		{
+			name: "hard delete",
+		},
+		{
+			name:       "soft delete",
+			softDelete: true,
+		},
+		{
+			name:       "soft delete archivable",
+			softDelete: true,
+			archivable: true,
+		},
+		{
+			name:       "atomic soft delete archivable",
+			softDelete: true,
+			archivable: true,
+			atomic:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{
+						Name: "Control", PluralName: "Controls", OperationType: "delete", DryRun: true,
+						Atomic: tt.atomic, SoftDelete: tt.softDelete, Archivable: tt.archivable,
+					},
+				},
+				EntImport: "github.com/example/app/internal/ent/generated",
+				Imports:   runtimeimports.Config{}.WithDefaults(),
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			if !tt.softDelete {
+				assert.Contains(t, out, "DeleteOneID(id)")
+				assert.Contains(t, out, "generated.ControlEdgeCleanup")
+				assert.NotContains(t, out, "SetDeletedAt")
+				assert.NotContains(t, imports, "time")
+
+				return
+			}
+
+			assert.NotContains(t, out, "DeleteOneID(id)")
+			assert.NotContains(t, out, "EdgeCleanup")
+			assert.Contains(t, imports, "time")
+
+			// the delete and its dry run use the same soft delete
+			assert.Equal(t, 1, strings.Count(out, "SetDeletedAt(time.Now())"))
+			assert.Contains(t, out, "func softDeleteControl(ctx context.Context, c *generated.Client, id string) error {")
+
+			if tt.atomic {
+				assert.Contains(t, out, "softDeleteControl(ctx, c, id)")
+			} else {
+				assert.Contains(t, out, "softDeleteControl(poolCtx, r.db, id)")
+			}
+
+			if !tt.archivable {
+				assert.NotContains(t, out, "SetStatus")
+				assert.NotContains(t, imports, runtimeimports.DefaultEnums)
+
+				return
+			}
+
+			// the status before the soft delete is kept to be restored
+			assert.Contains(t, out, "update.SetPreviousStatus(current.Status).SetStatus(enums.ControlStatusArchived)")
+			assert.Contains(t, out, "Where(control.StatusEQ(current.Status))")
+			assert.Contains(t, out, "if current.Status != enums.ControlStatusArchived {")
+			assert.Equal(t, "enums", imports[runtimeimports.DefaultEnums])
+			assert.Contains(t, imports, "github.com/example/app/internal/ent/generated/control")
+		})
+	}
+}
+
+func TestBulkTemplateExport(t *testing.T) {
+	tests := []struct {
+		name        string
+		object      Object
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "export",
+			object: Object{
+				Name:          "Control",
+				PluralName:    "Controls",
+				OperationType: "export",
+				ExportColumns: []ExportColumn{
+					{Header: "ID", GoName: "ID"},
+					{Header: "RefCode", GoName: "RefCode"},
+					{Header: "ProgramIDs"},
+				},
+			},
+			contains: []string{
+				"func (r *queryResolver) exportControlCSV(ctx context.Context, opts ...generated.ControlPaginateOption) (string, error)",
+				`"ID", "RefCode", "ProgramIDs"`,
+				`"RefCode": graphutils.FormatCSVValue(node.RefCode),`,
+				`graphutils.StreamCSVExport(ctx, "Control", header, func(w *graphutils.CSVExportWriter) error {`,
+				"r.db.Control.Query().Paginate(ctx, after, &first, nil, nil, opts...)",
+				"if err := w.WritePage(rows); err != nil {",
+				"}, uploadCSVExport)",
+			},
+			notContains: []string{"node.ProgramIDs", "exportCSVReferencesForSchema", "withTransactionalMutation", "strings.Builder"},
+		},
+		{
+			name: "export with custom columns",
+			object: Object{
+				Name:             "Control",
+				PluralName:       "Controls",
+				OperationType:    "export",
+				CSVFieldMappings: []CSVFieldMapping{{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"}},
+				ExportColumns: []ExportColumn{
+					{Header: "ID", GoName: "ID"},
+					{Header: "ControlOwnerEmail", GoName: "ControlOwnerID"},
+				},
+			},
+			contains: []string{
+				`"ControlOwnerEmail": graphutils.FormatCSVValue(node.ControlOwnerID),`,
+				`exportCSVReferencesForSchema(ctx, "Control", rows)`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects:   []Object{tt.object},
+				EntImport: "github.com/example/app/internal/ent/generated",
+				Imports:   runtimeimports.Config{}.WithDefaults(),
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			assert.NotContains(t, imports, "strings")
+			assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+
+			// gofmt aligns the header values, so compare without the line breaks
+			flat := strings.Join(strings.Fields(out), " ")
+
+			for _, s := range tt.contains {
+				assert.Contains(t, flat, strings.Join(strings.Fields(s), " "))
+			}
+
+			for _, s := range tt.notContains {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestBulkTemplateCSVHeader(t *testing.T) {
+	data := BulkResolverBuild{
+		Objects: []Object{
+			{
+				Name:          "Control",
+				PluralName:    "Controls",
+				OperationType: "create",
+				CSVHeaders:    []string{"RefCode", "OwnerId", "ControlOwnerEmail"},
+				RequiredCSVHeaders: []RequiredCSVHeader{
+					{Header: "RefCode"},
+					{Header: "OwnerId", Alternatives: []string{"ControlOwnerEmail"}},
+				},
+			},
+			{Name: "Control", PluralName: "Controls", OperationType: "delete"},
+		},
+		EntImport: "github.com/example/app/internal/ent/generated",
+		Imports:   runtimeimports.Config{}.WithDefaults(),
+	}
+
+	out, imports := renderBulk(t, data)
+
+	_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, imports, "io")
+	assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+
+	assert.Equal(t, 1, strings.Count(out, "func (r *mutationResolver) validateControlCSVHeader(file io.ReadSeeker) (io.ReadSeeker, error)"))
+	assert.Contains(t, out, "file, err := graphutils.TrimCSVHeaderMarkers(file)")
+	assert.Contains(t, out, `"RefCode": {},`)
+	assert.Contains(t, out, `"OwnerId": {"ControlOwnerEmail", },`)
+	assert.Contains(t, out, `graphutils.ValidateCSVHeader("control", header, known, required)`)
+	assert.Contains(t, out, "return file, nil")
+}
+
+func TestBulkTemplateDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		itemResults bool
+		dryRun      bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:   "dry run",
+			dryRun: true,
+			expected: []string{
+				"func (r *mutationResolver) dryRunBulk(ctx context.Context, fn func(ctx context.Context, c *generated.Client)) error {",
+				"tx.Rollback()",
+				"func (r *mutationResolver) dryRunBulkCreateControl(ctx context.Context, input []*generated.CreateControlInput)",
+				"c.Control.Create().SetInput(*data).Save(ctx)",
+				"func (r *mutationResolver) dryRunBulkUpdateControl(ctx context.Context, ids []string, input generated.UpdateControlInput)",
+				"func (r *mutationResolver) dryRunBulkUpdateCSVControl(ctx context.Context, inputs []*csvgenerated.ControlCSVUpdateInput)",
+				"func (r *mutationResolver) dryRunBulkDeleteControl(ctx context.Context, ids []string)",
+				"c.Control.DeleteOneID(id).Exec(ctx)",
+				"func (r *mutationResolver) dryRunBulkUpsertControl(ctx context.Context, rows []*graphutils.UpsertRow[generated.CreateControlInput])",
+				"existing, err := r.findControlUpsertRow(ctx, c, row)",
+				`graphutils.NewBulkError("control", "create")`,
+				"generated.IsConstraintError(err)",
+				"if bulkErr.HasErrors() {\n\t\treturn nil, bulkErr\n\t}",
+			},
+			notExpected: []string{"Results:", "BulkDryRunResults"},
+		},
+		{
+			name:        "dry run with item results",
+			dryRun:      true,
+			itemResults: true,
+			expected: []string{
+				`graphutils.NewBulkItemSuccess(i, "")`,
+				"graphutils.NewBulkItemSuccess(i, existing.ID)",
+				// the rows that would fail are returned in the results of the payload instead of a bulk error
+				"Results: graphutils.BulkDryRunResults(len(input), itemResults, bulkErr),",
+				"Results:       graphutils.BulkDryRunResults(len(ids), itemResults, bulkErr),",
+				"Results:       graphutils.BulkDryRunResults(len(inputs), itemResults, bulkErr),",
+				"Results:    graphutils.BulkDryRunResults(len(rows), itemResults, bulkErr),",
+				"NotUpdatedIDs: append([]string{}, bulkErr.FailedIDs...),",
+				"NotDeletedIDs: append([]string{}, bulkErr.FailedIDs...),",
+			},
+			notExpected: []string{"return nil, bulkErr\n\t}\n\n\treturn &ControlBulkCreatePayload{\n\t\tControls: []*generated.Control{},", "graphutils.BulkItemSuccesses("},
+		},
+		{
+			name: "without dry run",
+			// the upsert lookup is shared with the dry run but always generated
+			expected:    []string{"existing, err := r.findControlUpsertRow(ctx, c, row)"},
+			notExpected: []string{"dryRun", "tx.Rollback()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Control", PluralName: "Controls", OperationType: "create", DryRun: tt.dryRun},
+					{Name: "Control", PluralName: "Controls", OperationType: "update", HasCSVUpdateMutation: true, DryRun: tt.dryRun},
+					{Name: "Control", PluralName: "Controls", OperationType: "delete", DryRun: tt.dryRun},
+					{Name: "Control", PluralName: "Controls", OperationType: "upsert", DryRun: tt.dryRun},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				ItemResults:        tt.itemResults,
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestBulkTemplateBulkJob(t *testing.T) {
+	tests := []struct {
+		name        string
+		bulkJob     bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:    "bulk job",
+			bulkJob: true,
+			expected: []string{
+				"func (r *mutationResolver) enqueueBulkCreateControlJob(ctx context.Context, input []*generated.CreateControlInput) (*graphutils.BulkJobStatus, error) {",
+				"func (r *mutationResolver) enqueueBulkUpdateCSVControlJob(ctx context.Context, inputs []*csvgenerated.ControlCSVUpdateInput) (*graphutils.BulkJobStatus, error) {",
+				"r.bulkJobRunner().Enqueue(ctx, graphutils.BulkJob{",
+				`Operation: "create",`,
+				`Operation: "update",`,
+				"poolCtx := generated.NewContext(ctx, r.db)",
+				"r.db.Control.Create().SetInput(*data).Save(poolCtx)",
+				"existing.Update().SetInput(input.Input).Save(poolCtx)",
+				`progress.Failed(i, "", err)`,
+				"progress.Failed(i, input.ID, err)",
+				"progress.Succeeded(i, created.ID)",
+			},
+		},
+		{
+			name:        "without bulk job",
+			notExpected: []string{"bulkJobRunner", "graphutils.BulkJob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Control", PluralName: "Controls", OperationType: "create", BulkJob: tt.bulkJob},
+					{Name: "Control", PluralName: "Controls", OperationType: "update", HasCSVUpdateMutation: true, BulkJob: tt.bulkJob},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			if tt.bulkJob {
+				assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+			} else {
+				assert.NotContains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+			}
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestBulkTemplateFilePerEntity(t *testing.T) {
+	data := BulkResolverBuild{
+		Objects: []Object{
+			{Name: "Control", PluralName: "Controls", OperationType: "create", DryRun: true},
+			{Name: "Control", PluralName: "Controls", OperationType: "delete"},
+		},
+		EntImport: "github.com/example/app/internal/ent/generated",
+		Imports:   runtimeimports.Config{}.WithDefaults(),
+	}
+
+	dryRunHelper := "func (r *mutationResolver) dryRunBulk(ctx context.Context"
+
+	tests := []struct {
+		name        string
+		sharedFile  bool
+		entityFile  bool
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:     "single file",
+			expected: []string{dryRunHelper, "func (r *mutationResolver) bulkCreateControl", "func (r *mutationResolver) bulkDeleteControl"},
+		},
+		{
+			name:        "shared file",
+			sharedFile:  true,
+			expected:    []string{dryRunHelper},
+			notExpected: []string{"bulkCreateControl", "bulkDeleteControl"},
+		},
+		{
+			name:        "entity file",
+			entityFile:  true,
+			expected:    []string{"func (r *mutationResolver) bulkCreateControl", "func (r *mutationResolver) dryRunBulkCreateControl", "func (r *mutationResolver) bulkDeleteControl"},
+			notExpected: []string{dryRunHelper},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := data
+			file.SharedFile = tt.sharedFile
+			file.EntityFile = tt.entityFile
+
+			out, _ := renderBulk(t, file)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
+
+func TestBulkTemplateAuthzPrepass(t *testing.T) {
+	checks := []CreateAuthzCheck{
+		{Name: "ownerID", GoName: "OwnerID", ObjectType: "organization", Relation: "can_create_subcontrol", Nillable: true},
+		{Name: "controlID", GoName: "ControlID", ObjectType: "control", Relation: "can_edit"},
+	}
+
+	tests := []struct {
+		name        string
+		precheck    bool
+		checks      []CreateAuthzCheck
+		atomic      bool
+		itemResults bool
+		workers     int
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:     "create skips the denied rows and returns them in the payload",
+			precheck: true,
+			checks:   checks,
+			expected: []string{
+				"func (r *mutationResolver) deniedBulkCreateSubcontrolRows(ctx context.Context, input []*generated.CreateSubcontrolInput) map[int]struct{} {",
+				"denied := r.deniedBulkCreateSubcontrolRows(ctx, input)",
+				"deniedRows := graphutils.DeniedRows(denied)",
+				"input = allowed",
+				"DeniedRows: deniedRows,",
+				"ownerIDRows.Add(i, *data.OwnerID)",
+				"controlIDRows.Add(i, data.ControlID)",
+				`r.filterAuthorizedIDs(ctx, ownerIDRows.IDs(), "organization", "can_create_subcontrol")`,
+				`r.filterAuthorizedIDs(ctx, controlIDRows.IDs(), "control", "can_edit")`,
+			},
+			notExpected: []string{`bulkErr.Add(i, "", rout.ErrPermissionDenied)`},
+		},
+		{
+			name:        "create reports the denied rows in the results",
+			precheck:    true,
+			checks:      checks,
+			itemResults: true,
+			expected: []string{
+				"denied := r.deniedBulkCreateSubcontrolRows(ctx, input)",
+				"DeniedRows: deniedRows,",
+				`itemResults = append(itemResults, graphutils.NewBulkItemFailure(i, "", rout.ErrPermissionDenied))`,
+			},
+			notExpected: []string{"input = allowed"},
+		},
+		{
+			name:        "create without owner or parent ids",
+			precheck:    true,
+			notExpected: []string{"deniedBulkCreateSubcontrolRows", "DeniedRows"},
+		},
+		{
+			name:     "CSV update reports the denied rows as not updated",
+			precheck: true,
+			expected: []string{
+				"func (r *mutationResolver) deniedBulkUpdateCSVSubcontrolRows(ctx context.Context, inputs []*csvgenerated.SubcontrolCSVUpdateInput) map[int]struct{} {",
+				`return rows.Denied(r.filterAuthorizedIDs(ctx, rows.IDs(), "subcontrol", fgax.CanEdit))`,
+				"denied := r.deniedBulkUpdateCSVSubcontrolRows(ctx, inputs)",
+				"notUpdatedIDs = append(notUpdatedIDs, input.ID)",
+				"NotUpdatedIDs: notUpdatedIDs,",
+			},
+		},
+		{
+			name:        "parallel CSV update with item results",
+			precheck:    true,
+			workers:     4,
+			itemResults: true,
+			expected: []string{
+				"denied := r.deniedBulkUpdateCSVSubcontrolRows(ctx, inputs)",
+				"itemResults[i] = graphutils.NewBulkItemFailure(i, input.ID, rout.ErrPermissionDenied)",
+			},
+		},
+		{
+			name:     "atomic CSV update fails before any row is updated",
+			precheck: true,
+			atomic:   true,
+			expected: []string{
+				"denied := r.deniedBulkUpdateCSVSubcontrolRows(ctx, inputs)",
+				"bulkErr.Add(i, input.ID, rout.ErrPermissionDenied)",
+			},
+			notExpected: []string{"notUpdatedIDs = append(notUpdatedIDs, input.ID)"},
+		},
+		{
+			name:        "without the pre-check",
+			notExpected: []string{"deniedBulkCreateSubcontrolRows", "deniedBulkUpdateCSVSubcontrolRows", "DeniedRows", "rout.ErrPermissionDenied"},
+		},
+		{
+			name:        "atomic CSV update without the pre-check",
+			atomic:      true,
+			notExpected: []string{"deniedBulkUpdateCSVSubcontrolRows", "bulkErr.Add(i, input.ID, rout.ErrPermissionDenied)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := BulkResolverBuild{
+				Objects: []Object{
+					{Name: "Subcontrol", PluralName: "Subcontrols", OperationType: "create", AuthzPrecheck: tt.precheck, CreateAuthzChecks: tt.checks},
+					{Name: "Subcontrol", PluralName: "Subcontrols", OperationType: "update", AuthzPrecheck: tt.precheck, HasCSVUpdateMutation: true, Atomic: tt.atomic, UpdateWorkers: tt.workers},
+				},
+				EntImport:          "github.com/example/app/internal/ent/generated",
+				CSVGeneratedImport: "github.com/example/app/internal/ent/csvgenerated",
+				Imports:            runtimeimports.Config{}.WithDefaults(),
+				ItemResults:        tt.itemResults,
+			}
+
+			out, imports := renderBulk(t, data)
+
+			_, err := parser.ParseFile(token.NewFileSet(), "bulk.go", "package graphapi\n"+out, 0)
+			require.NoError(t, err)
+
+			if tt.precheck || tt.atomic || tt.itemResults {
+				assert.Contains(t, imports, "github.com/theopenlane/gqlgen-plugins/graphutils")
+			}
+
+			for _, s := range tt.expected {
+				assert.Contains(t, out, s)
+			}
+
+			for _, s := range tt.notExpected {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
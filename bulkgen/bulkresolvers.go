@@ -4,8 +4,13 @@ import (
 	_ "embed"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -13,8 +18,14 @@ import (
 	"github.com/99designs/gqlgen/codegen/templates"
 	"github.com/99designs/gqlgen/plugin"
 	"github.com/gertd/go-pluralize"
+	"github.com/goccy/go-yaml"
 	"github.com/rs/zerolog/log"
 	"github.com/stoewer/go-strcase"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+	"github.com/theopenlane/gqlgen-plugins/graphutils"
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 //go:embed bulk.gotpl
@@ -76,12 +87,176 @@ func WithCSVGeneratedPackage(pkg string) Options {
 
 // WithCSVFieldMappingsFile sets the path to the JSON file containing CSV field mappings.
 // This file is generated by entx and contains custom CSV column definitions.
+// The path can also be a YAML file (.yaml or .yml), a directory of JSON and YAML mapping files,
+// or a glob pattern matching mapping files, the mappings of all files are merged per schema
 func WithCSVFieldMappingsFile(path string) Options {
 	return func(p *Plugin) {
 		p.CSVFieldMappingsFile = path
 	}
 }
 
+// WithCSVFieldMappings adds CSV field mappings declared in go, keyed by schema name.
+// The mappings are merged per schema with the mappings of the CSV field mappings file
+// and the mappings of earlier calls
+func WithCSVFieldMappings(mappings map[string][]CSVFieldMapping) Options {
+	return func(p *Plugin) {
+		p.CSVFieldMappings = mergeCSVFieldMappings(p.CSVFieldMappings, mappings)
+	}
+}
+
+// WithStrictCSVFieldMappings returns an error from the code generation when the CSV field mappings file
+// can not be read or a mapping does not match the graphql schema, instead of skipping the file or the
+// custom columns. Each mapping must be for a schema in the graphql schema with a target field on its
+// create input, a list target field for slice mappings, and a column that is not a field of the input
+func WithStrictCSVFieldMappings() Options {
+	return func(p *Plugin) {
+		p.StrictCSVFieldMappings = true
+	}
+}
+
+// WithMutationNamePatterns sets the names of the bulk and CSV bulk mutations, replacing the DefaultMutationNamePatterns.
+// Each pattern has an {entity} placeholder for the object name, {Entity} when the name starts with an uppercase letter,
+// and an {op} placeholder for create, update, delete, upsert or createOrUpdate, e.g. {op}Many{Entity}. A pattern
+// without {op} is for the operation in its text, e.g. {entity}BatchCreate. Patterns containing CSV are for the CSV
+// bulk mutations. A mutation matching more than one pattern is an error
+func WithMutationNamePatterns(patterns ...string) Options {
+	return func(p *Plugin) {
+		p.MutationNamePatterns = patterns
+	}
+}
+
+// WithRuntimeImports sets the import paths of the runtime packages referenced by the generated code,
+// such as the logger, error helpers and authz package, empty paths use the defaults of the openlane core layout
+func WithRuntimeImports(imports runtimeimports.Config) Options {
+	return func(p *Plugin) {
+		p.RuntimeImports = imports
+	}
+}
+
+// WithAtomicBulkOperations makes the bulk update and delete resolvers of all objects all-or-nothing,
+// any failure returns a graphutils.BulkError with the failed ids and the request transaction is rolled back
+func WithAtomicBulkOperations() Options {
+	return func(p *Plugin) {
+		p.AtomicBulkOperations = true
+	}
+}
+
+// WithAtomicBulkObjects makes the bulk update and delete resolvers of the given objects all-or-nothing,
+// e.g. WithAtomicBulkObjects("Control", "Policy")
+func WithAtomicBulkObjects(objects ...string) Options {
+	return func(p *Plugin) {
+		p.AtomicBulkObjects = append(p.AtomicBulkObjects, objects...)
+	}
+}
+
+// WithBulkItemResults adds a result for each item to the bulk payloads with its index, id, status and
+// the code and message of the error when it failed, the payloads in the graphql schema must have a
+// results field of type [BulkItemResult!] bound to graphutils.BulkItemResult
+func WithBulkItemResults() Options {
+	return func(p *Plugin) {
+		p.BulkItemResults = true
+	}
+}
+
+// WithBulkCreateChunkSize splits the bulk creates into CreateBulk calls of at most size rows to stay
+// under the database parameter limits for large inputs, the chunks are created in the request transaction
+// unless WithBulkCreateChunkCommit is set. A size of 0 creates all rows in a single call
+func WithBulkCreateChunkSize(size int) Options {
+	return func(p *Plugin) {
+		p.BulkCreateChunkSize = size
+	}
+}
+
+// WithBulkCreateChunkCommit creates and commits each bulk create chunk in its own transaction instead of
+// the request transaction, chunks committed before a failure are not rolled back. It requires
+// WithBulkCreateChunkSize and is ignored with WithBulkItemResults, which creates the rows one at a time
+func WithBulkCreateChunkCommit() Options {
+	return func(p *Plugin) {
+		p.BulkCreateChunkCommit = true
+	}
+}
+
+// WithParallelBulkUpdate updates the ids of the bulk update and the rows of the CSV bulk update in parallel
+// on the connection pool, like the bulk delete, with at most workers concurrent updates per request.
+// The updates are not part of the request transaction so objects with atomic bulk operations are still
+// updated serially. A value of 0 updates serially
+func WithParallelBulkUpdate(workers int) Options {
+	return func(p *Plugin) {
+		p.BulkUpdateWorkers = workers
+	}
+}
+
+// WithFilePerEntity writes the bulk resolver functions of each object to its own bulk_<entity>.go file in the
+// resolver directory instead of a single bulk.go, which then only has the functions shared by all objects.
+// The generated files of objects that no longer have bulk mutations are removed
+func WithFilePerEntity() Options {
+	return func(p *Plugin) {
+		p.FilePerEntity = true
+	}
+}
+
+// WithUpsertKeys sets the natural key used by the bulk upsert of each object to match rows without an id
+// to existing objects, keyed by object name with the graphql name of a field of the create input,
+// e.g. WithUpsertKeys(map[string]string{"Control": "refCode"}). Objects without a key are only matched by id
+func WithUpsertKeys(keys map[string]string) Options {
+	return func(p *Plugin) {
+		if p.UpsertKeys == nil {
+			p.UpsertKeys = map[string]string{}
+		}
+
+		maps.Copy(p.UpsertKeys, keys)
+	}
+}
+
+// WithSoftDeleteSchemas sets schemas that are soft deleted by the bulk delete instead of being removed, the
+// same as the resolvergen option: the deleted_at time is set (and the status is archived for schemas also set
+// with WithArchivableSchemas) and edges are not cleaned up
+func WithSoftDeleteSchemas(schemas []string) Options {
+	return func(p *Plugin) {
+		p.SoftDeleteSchemas = schemas
+	}
+}
+
+// WithArchivableSchemas sets schemas that have a status of archived, soft deleted schemas in the list
+// are archived by the bulk delete
+func WithArchivableSchemas(schemas []string) Options {
+	return func(p *Plugin) {
+		p.ArchivableSchemas = schemas
+	}
+}
+
+// WithBulkAuthzPrecheck checks the authorization of every row of the bulk creates and CSV bulk updates before any
+// row is saved, so the rows the user is not allowed to save are reported instead of failing the batch halfway.
+// The CSV bulk update checks CanEdit on the id of each row, the bulk create checks the create relation of the
+// organization of the ownerID, e.g. can_create_control, and the relations set with WithCreateAuthzRelations
+func WithBulkAuthzPrecheck() Options {
+	return func(p *Plugin) {
+		p.BulkAuthzPrecheck = true
+	}
+}
+
+// WithCreateAuthzRelations sets the relations the user must have on the id fields of the create inputs that are
+// checked before a bulk create, keyed by the graphql name of the field, e.g. {"programID": "can_edit"}. The object
+// type of the id is the object the field is named after, e.g. program. Other id fields are not checked, and a
+// relation for ownerID replaces the create relation of the organization. Only used with WithBulkAuthzPrecheck
+func WithCreateAuthzRelations(relations map[string]string) Options {
+	return func(p *Plugin) {
+		if p.CreateAuthzRelations == nil {
+			p.CreateAuthzRelations = map[string]string{}
+		}
+
+		maps.Copy(p.CreateAuthzRelations, relations)
+	}
+}
+
+// WithDryRun records the bulk objects and operations in the report instead of
+// generating the bulk resolvers and sample CSVs, no files are written
+func WithDryRun(report *genreport.Report) Options {
+	return func(p *Plugin) {
+		p.DryRunReport = report
+	}
+}
+
 // Plugin is a gqlgen plugin to generate bulk resolver functions used for mutations
 type Plugin struct {
 	// ModelPackage is the package name for the gqlgen model
@@ -94,8 +269,42 @@ type Plugin struct {
 	CSVOutputPath string
 	// CSVGeneratedPackage is the import path for the csvgenerated package
 	CSVGeneratedPackage string
-	// CSVFieldMappingsFile is the path to the JSON file containing CSV field mappings
+	// CSVFieldMappingsFile is the path to the JSON or YAML file, directory or glob pattern of the CSV field mappings
 	CSVFieldMappingsFile string
+	// CSVFieldMappings are the CSV field mappings declared in go, keyed by schema name
+	CSVFieldMappings CSVFieldMappingsJSON
+	// StrictCSVFieldMappings returns an error when the CSV field mappings can not be read or do not match the schema
+	StrictCSVFieldMappings bool
+	// RuntimeImports are the import paths of the runtime packages referenced by the generated code
+	RuntimeImports runtimeimports.Config
+	// DryRunReport when set records the bulk objects instead of writing the generated files
+	DryRunReport *genreport.Report
+	// AtomicBulkOperations makes the bulk update and delete resolvers of all objects all-or-nothing
+	AtomicBulkOperations bool
+	// AtomicBulkObjects are the objects with all-or-nothing bulk update and delete resolvers
+	AtomicBulkObjects []string
+	// BulkItemResults adds the per item results to the bulk payloads
+	BulkItemResults bool
+	// BulkCreateChunkSize is the max number of rows of each CreateBulk call, 0 creates all rows in one call
+	BulkCreateChunkSize int
+	// BulkCreateChunkCommit commits each bulk create chunk in its own transaction
+	BulkCreateChunkCommit bool
+	// UpsertKeys are the natural keys used by the bulk upsert of each object, keyed by object name
+	UpsertKeys map[string]string
+	// BulkUpdateWorkers is the max number of concurrent updates of a bulk update, 0 updates serially
+	BulkUpdateWorkers int
+	// MutationNamePatterns are the names of the bulk and CSV bulk mutations, empty uses DefaultMutationNamePatterns
+	MutationNamePatterns []string
+	// FilePerEntity writes the functions of each object to a bulk_<entity>.go file instead of a single bulk.go
+	FilePerEntity bool
+	// SoftDeleteSchemas are the schemas that are soft deleted by the bulk delete instead of being removed
+	SoftDeleteSchemas []string
+	// ArchivableSchemas are the schemas with an archived status, set by the bulk delete of soft deleted schemas
+	ArchivableSchemas []string
+	// BulkAuthzPrecheck checks the authorization of every row of the bulk creates and CSV bulk updates before any row is saved
+	BulkAuthzPrecheck bool
+	// CreateAuthzRelations are the relations checked on the id fields of the create inputs, keyed by the field name
+	CreateAuthzRelations map[string]string
 }
 
 // Name returns the name of the plugin
@@ -117,6 +326,105 @@ type BulkResolverBuild struct {
 	ModelPackage string
 	// CSVGeneratedImport is the import path for the csvgenerated package
 	CSVGeneratedImport string
+	// Imports are the import paths of the runtime packages referenced by the template
+	Imports runtimeimports.Config
+	// ItemResults adds the per item results to the bulk payloads
+	ItemResults bool
+	// CreateChunkSize is the max number of rows of each CreateBulk call, 0 creates all rows in one call
+	CreateChunkSize int
+	// CommitCreateChunks commits each bulk create chunk in its own transaction
+	CommitCreateChunks bool
+	// SharedFile renders only the functions shared by all objects, such as dryRunBulk, without the objects
+	SharedFile bool
+	// EntityFile renders the functions of the objects of one entity without the functions shared by all objects
+	EntityFile bool
+}
+
+// FileObjects returns the objects rendered in the file, none for the shared file
+func (b BulkResolverBuild) FileObjects() []Object {
+	if b.SharedFile {
+		return nil
+	}
+
+	return b.Objects
+}
+
+// HasAtomicObjects returns true when any object has all-or-nothing bulk operations, used to
+// only import graphutils when it is referenced by the generated code
+func (b BulkResolverBuild) HasAtomicObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.Atomic && (o.OperationType == "update" || o.OperationType == "delete")
+	})
+}
+
+// HasExportObjects returns true when any object has a CSV export query
+func (b BulkResolverBuild) HasExportObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.OperationType == "export"
+	})
+}
+
+// HasCSVHeaderChecks returns true when any object validates the header of a CSV upload
+func (b BulkResolverBuild) HasCSVHeaderChecks() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return len(o.CSVHeaders) > 0
+	})
+}
+
+// HasDryRunObjects returns true when any object has a bulk operation that can be run as a dry run
+func (b BulkResolverBuild) HasDryRunObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.DryRun
+	})
+}
+
+// HasBulkJobObjects returns true when any object has a CSV mutation that is run as a bulk job
+func (b BulkResolverBuild) HasBulkJobObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.BulkJob
+	})
+}
+
+// HasAuthzPrepassObjects returns true when any object checks the authorization of its rows before a bulk
+// create or a CSV bulk update, used to only import graphutils when it is referenced by the generated code
+func (b BulkResolverBuild) HasAuthzPrepassObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		if len(o.CreateAuthzChecks) > 0 {
+			return true
+		}
+
+		return o.AuthzPrecheck && o.OperationType == "update" && (o.HasCSVUpdateMutation || o.BulkJob) && b.CSVGeneratedImport != ""
+	})
+}
+
+// HasSoftDeleteObjects returns true when any object is soft deleted by its bulk delete
+func (b BulkResolverBuild) HasSoftDeleteObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.OperationType == "delete" && o.SoftDelete
+	})
+}
+
+// HasArchivableSoftDeleteObjects returns true when any object is archived by its bulk delete, used to
+// only import the enums when they are referenced by the generated code
+func (b BulkResolverBuild) HasArchivableSoftDeleteObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.OperationType == "delete" && o.SoftDelete && o.Archivable
+	})
+}
+
+// HasRowResultObjects returns true when any object has a bulk create or upsert that reports the result of each row
+// when item results are on
+func (b BulkResolverBuild) HasRowResultObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.OperationType == "create" || o.OperationType == "upsert"
+	})
+}
+
+// HasUpsertObjects returns true when any object has a bulk upsert operation
+func (b BulkResolverBuild) HasUpsertObjects() bool {
+	return slices.ContainsFunc(b.Objects, func(o Object) bool {
+		return o.OperationType == "upsert"
+	})
 }
 
 // Object is a struct to hold the object name for the bulk resolver
@@ -127,6 +435,8 @@ type Object struct {
 	PluralName string
 	// Fields of the object
 	Fields []string
+	// SampleFields are the fields of the create input with their example values for the sample CSV
+	SampleFields []SampleField
 	// AppendFields is the list of fields that can be appended in the update mutation
 	AppendFields []string
 	// OperationType indicates whether this is a create or delete operation
@@ -135,6 +445,120 @@ type Object struct {
 	HasCSVUpdateMutation bool
 	// CSVFieldMappings contains custom CSV column mappings for this object
 	CSVFieldMappings []CSVFieldMapping
+	// Atomic indicates the bulk update and delete operations roll back the whole batch on any failure
+	Atomic bool
+	// HasCSVUpsertMutation indicates if this object has a CSV bulk upsert mutation
+	HasCSVUpsertMutation bool
+	// UpsertKey is the natural key used by the bulk upsert to match rows without an id to existing objects
+	UpsertKey *UpsertKey
+	// UpsertEdges are the edges of the create input that the bulk upsert replaces on the objects it updates
+	UpsertEdges []UpsertEdge
+	// UpdateWorkers is the max number of concurrent updates of the bulk update, 0 updates serially
+	UpdateWorkers int
+	// ExportColumns are the columns of the CSV export of the object, in order
+	ExportColumns []ExportColumn
+	// CSVHeaders are the known headers of the CSV upload of the operation, empty when there is no CSV upload
+	CSVHeaders []string
+	// RequiredCSVHeaders are the columns that must be in the header of the CSV upload of the operation
+	RequiredCSVHeaders []RequiredCSVHeader
+	// DryRun indicates a bulk or CSV mutation of the operation has a dryRun argument to validate the rows without saving them
+	DryRun bool
+	// BulkJob indicates a job variant of the CSV mutation of the operation enqueues the rows to be saved in the background
+	BulkJob bool
+	// AuthzPrecheck indicates the rows of the bulk create or CSV bulk update are checked before any row is saved
+	AuthzPrecheck bool
+	// CreateAuthzChecks are the owner and parent ids of the create input checked before any row of a bulk create is created
+	CreateAuthzChecks []CreateAuthzCheck
+	// SoftDelete indicates the bulk delete sets the deleted_at time instead of removing the objects and their edges
+	SoftDelete bool
+	// Archivable indicates the object has an archived status, set by the bulk delete when the object is soft deleted
+	Archivable bool
+}
+
+// CreateAuthzCheck is an owner or parent id of the create input of an object, the rows of a bulk create with an id
+// the user does not have the relation on are reported instead of being created
+type CreateAuthzCheck struct {
+	// Name is the name of the field of the create input, e.g. controlID
+	Name string
+	// GoName is the go name of the field of the create input, e.g. ControlID
+	GoName string
+	// ObjectType is the authorization object type of the id, e.g. control
+	ObjectType string
+	// Relation is the relation checked on the id, e.g. can_create_control for the owner id
+	Relation string
+	// Nillable indicates the field is optional and is a pointer on the create input
+	Nillable bool
+}
+
+// RequiredCSVHeader is a required column of a CSV upload
+type RequiredCSVHeader struct {
+	// Header is the header of the required column, e.g. OwnerId
+	Header string
+	// Alternatives are the custom columns that can be set instead, e.g. OwnerEmail
+	Alternatives []string
+}
+
+// ParallelUpdate returns true when the bulk update runs on the connection pool, an atomic bulk update
+// must run in the request transaction so it is always updated serially
+func (o Object) ParallelUpdate() bool {
+	return o.UpdateWorkers > 0 && !o.Atomic
+}
+
+// CSVHeaderValidator returns the name of the generated function that validates the header of the CSV upload
+// of the operation, e.g. validateControlCSVHeader or validateControlCSVUpdateHeader
+func (o Object) CSVHeaderValidator() string {
+	switch o.OperationType {
+	case "update":
+		return "validate" + o.Name + "CSVUpdateHeader"
+	case "upsert":
+		return "validate" + o.Name + "CSVUpsertHeader"
+	default:
+		return "validate" + o.Name + "CSVHeader"
+	}
+}
+
+// ExportColumn is a column of the CSV export of an object
+type ExportColumn struct {
+	// Header is the CSV header of the column, the same as the sample CSV, e.g. AssignedToUserEmail
+	Header string
+	// GoName is the go name of the field of the object the column is filled from, e.g. AssignedToUserID,
+	// empty when the object does not have the field, such as the ids of edges
+	GoName string
+}
+
+// SampleField is an input field of a sample CSV
+type SampleField struct {
+	// Name is the CSV header of the field, e.g. RefCode
+	Name string
+	// Required indicates the field is required on the input
+	Required bool
+	// Type is the graphql type of the field, e.g. String! or [ID!]
+	Type string
+	// EnumValues are the allowed values of enum fields
+	EnumValues []string
+	// Example is an example value that is valid for the type of the field
+	Example string
+}
+
+// UpsertKey is the natural key of an object used by the bulk upsert
+type UpsertKey struct {
+	// Name is the graphql name of the field, e.g. refCode
+	Name string
+	// GoName is the go name of the field on the create input and in the ent predicates, e.g. RefCode
+	GoName string
+	// Nillable indicates the field is a pointer on the create input
+	Nillable bool
+}
+
+// UpsertEdge is a list of ids of the create input of an object, the bulk upsert clears the edge of the objects
+// it updates before the ids are added so an updated row replaces the edge instead of appending to it
+type UpsertEdge struct {
+	// Name is the graphql name of the field of the create input, e.g. programIDs
+	Name string
+	// GoName is the go name of the field of the create input, e.g. ProgramIDs
+	GoName string
+	// Clear is the method of the update builder that clears the edge, e.g. ClearPrograms
+	Clear string
 }
 
 // CSVFieldMapping represents a custom CSV column that maps to a field.
@@ -148,7 +572,7 @@ type CSVFieldMapping struct {
 	IsSlice bool `json:"isSlice"`
 }
 
-// CSVFieldMappingsJSON is the top-level structure for the JSON mappings file.
+// CSVFieldMappingsJSON is the top-level structure for the JSON and YAML mappings files.
 // Maps schema names to their CSV field mappings.
 type CSVFieldMappingsJSON map[string][]CSVFieldMapping
 
@@ -161,24 +585,21 @@ func (m *Plugin) GenerateCode(data *codegen.Data) error {
 	return m.generateSingleFile(*data)
 }
 
-// loadCSVFieldMappings reads the CSV field mappings from the JSON file.
-// Returns an empty map if the file doesn't exist or can't be read.
+// loadCSVFieldMappings reads the CSV field mappings from the mappings files.
+// Returns an empty map if the files don't exist or can't be read.
 func loadCSVFieldMappings(filePath string) CSVFieldMappingsJSON {
 	if filePath == "" {
 		return nil
 	}
 
-	file, err := os.Open(filePath)
-	if err != nil {
+	mappings, err := readCSVFieldMappings(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
 		log.Debug().Err(err).Str("path", filePath).Msg("CSV field mappings file not found, skipping custom columns")
 		return nil
 	}
 
-	defer file.Close()
-
-	var mappings CSVFieldMappingsJSON
-	if err := json.NewDecoder(file).Decode(&mappings); err != nil {
-		log.Warn().Err(err).Str("path", filePath).Msg("failed to decode CSV field mappings JSON")
+	if err != nil {
+		log.Warn().Err(err).Str("path", filePath).Msg("failed to read CSV field mappings, skipping custom columns")
 		return nil
 	}
 
@@ -187,227 +608,1226 @@ func loadCSVFieldMappings(filePath string) CSVFieldMappingsJSON {
 	return mappings
 }
 
-// generateSingleFile generates the bulk resolver code, this is all done in a single file and
-// used by the resolvergen plugin for each bulk resolver
-func (m *Plugin) generateSingleFile(data codegen.Data) error {
-	inputData := BulkResolverBuild{
-		Objects:            []Object{},
-		ModelImport:        m.ModelPackage,
-		EntImport:          m.EntGeneratedPackage,
-		GraphQLImport:      m.GraphQLImport,
-		CSVGeneratedImport: m.CSVGeneratedPackage,
+// readCSVFieldMappings decodes the CSV field mappings files of the path and merges them per schema
+func readCSVFieldMappings(path string) (CSVFieldMappingsJSON, error) {
+	files, err := csvFieldMappingsFiles(path)
+	if err != nil {
+		return nil, err
 	}
 
-	// Build a set of object names that have CSV bulk mutations.
-	// Uses case-insensitive prefix matching to handle naming variations,
-	// while only matching mutations that start with expected prefixes to avoid
-	// false positives with custom resolvers containing these substrings.
-	csvBulkMutations := make(map[string]bool)
-
-	for _, f := range data.Schema.Mutation.Fields {
-		if objectName := extractObjectNameFromCSVMutation(f.Name); objectName != "" {
-			csvBulkMutations[objectName] = true
-		}
-	}
+	var mappings CSVFieldMappingsJSON
 
-	// only add the model package if the import is not empty
-	if m.ModelPackage != "" {
-		modelPkg := data.Config.Model.Package
-		if modelPkg != "" {
-			modelPkg += "."
+	for _, file := range files {
+		fileMappings, err := readCSVFieldMappingsFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
 		}
 
-		inputData.ModelPackage = modelPkg
-	}
-
-	if m.CSVOutputPath == "" {
-		m.CSVOutputPath = data.Config.Resolver.Dir() + "/csv"
-	}
-
-	// create the directory if it does not exist
-	if _, err := os.Stat(m.CSVOutputPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(m.CSVOutputPath, os.ModePerm); err != nil {
-			return err
-		}
+		mappings = mergeCSVFieldMappings(mappings, fileMappings)
 	}
 
-	// Load CSV field mappings from JSON file if configured
-	csvFieldMappings := loadCSVFieldMappings(m.CSVFieldMappingsFile)
+	return mappings, nil
+}
 
-	for _, f := range data.Schema.Mutation.Fields {
-		lowerName := strings.ToLower(f.Name)
+// csvFieldMappingsFiles returns the mappings files of the path: the file itself, the JSON and YAML files
+// of a directory, or the files matching a glob pattern, sorted so the files are merged in a stable order
+func csvFieldMappingsFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
 
-		// if the field is a bulk create or delete mutation, add it to the list of objects
-		// we skip csv bulk mutations because they will reuse the same functions
-		if strings.Contains(lowerName, "bulk") && !strings.Contains(lowerName, "csv") {
-			var objectName, operationType string
+	switch {
+	case err == nil && info.IsDir():
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
 
-			switch {
-			case strings.Contains(lowerName, "createbulk"):
-				objectName = strings.Replace(f.Name, "createBulk", "", 1)
-				operationType = "create"
-			case strings.Contains(lowerName, "bulkcreate"):
-				objectName = strings.Replace(f.Name, "bulkCreate", "", 1)
-				operationType = "create"
-			case strings.Contains(lowerName, "updatebulk"):
-				objectName = strings.Replace(f.Name, "updateBulk", "", 1)
-				operationType = "update"
-			case strings.Contains(lowerName, "bulkupdate"):
-				objectName = strings.Replace(f.Name, "bulkUpdate", "", 1)
-				operationType = "update"
-			case strings.Contains(lowerName, "deletebulk"):
-				objectName = strings.Replace(f.Name, "deleteBulk", "", 1)
-				operationType = "delete"
-			case strings.Contains(lowerName, "bulkdelete"):
-				objectName = strings.Replace(f.Name, "bulkDelete", "", 1)
-				operationType = "delete"
-			default:
-				continue
-			}
+		var files []string
 
-			object := Object{
-				Name:                 objectName,
-				PluralName:           pluralFieldName(objectName),
-				Fields:               getCreateInputFields(objectName, data),
-				AppendFields:         getUpdateAppendFields(objectName, data),
-				OperationType:        operationType,
-				HasCSVUpdateMutation: csvBulkMutations[objectName],
-				CSVFieldMappings:     csvFieldMappings[objectName],
+		for _, entry := range entries {
+			if !entry.IsDir() && isCSVFieldMappingsFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
 			}
+		}
 
-			inputData.Objects = append(inputData.Objects, object)
+		return files, nil
+	case err == nil:
+		return []string{path}, nil
+	case errors.Is(err, fs.ErrNotExist) && strings.ContainsAny(path, "*?["):
+		files, err := filepath.Glob(path)
+		if err != nil {
+			return nil, err
+		}
 
-			// Generate and write the CSV file only for create operations
-			if operationType == "create" {
-				if err := generateSampleCSV(object, m.CSVOutputPath); err != nil {
-					return err
-				}
-			}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no files match %s: %w", path, fs.ErrNotExist)
 		}
-	}
 
-	// render the bulk resolver template
-	return templates.Render(templates.Options{
-		PackageName: data.Config.Resolver.Package,            // use the resolver package
-		Filename:    data.Config.Resolver.Dir() + "/bulk.go", // write to the resolver directory
-		FileNotice:  `// THIS CODE IS REGENERATED BY github.com/theopenlane/core/pkg/gqlplugin. DO NOT EDIT.`,
-		Data:        inputData,
-		Funcs: template.FuncMap{
-			"toLower":     strings.ToLower,
-			"toSnakeCase": strcase.SnakeCase,
-		},
-		Packages: data.Config.Packages,
-		Template: bulkTemplate,
-	})
-}
+		slices.Sort(files)
 
-// getCreateInputFields returns the list of fields available in the Create<object>Input
-func getCreateInputFields(objectName string, data codegen.Data) (inputFields []string) {
-	inputTypeName := "Create" + objectName + "Input"
-	if inputType, ok := data.Schema.Types[inputTypeName]; ok {
-		for _, f := range inputType.Fields {
-			inputFields = append(inputFields, strcase.UpperCamelCase(f.Name))
-		}
+		return files, nil
+	default:
+		return nil, err
 	}
-
-	return inputFields
 }
 
-// getUpdateAppendFields returns the list of fields that are appendable in the bulk update mutation
-func getUpdateAppendFields(objectName string, data codegen.Data) (appendFields []string) {
-	inputTypeName := "Update" + objectName + "Input"
-	if inputType, ok := data.Schema.Types[inputTypeName]; ok {
-		for _, f := range inputType.Fields {
-			if strings.Contains(f.Name, "append") {
-				appendFields = append(appendFields, templates.ToGo(f.Name))
-			}
-		}
+// isCSVFieldMappingsFile returns true for the JSON and YAML files read from a mappings directory
+func isCSVFieldMappingsFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
 	}
-
-	return appendFields
 }
 
-// generateSampleCSV generates a sample CSV file for the given object.
-// It includes both standard input fields and custom CSV column mappings from entx annotations.
-func generateSampleCSV(object Object, outputPath string) error {
-	headers := make([]string, 0, len(object.Fields)+len(object.CSVFieldMappings))
-	headers = append(headers, object.Fields...)
-
-	for _, mapping := range object.CSVFieldMappings {
-		headers = append(headers, mapping.CSVColumn)
+// readCSVFieldMappingsFile decodes a CSV field mappings file, files with a .yaml or .yml extension are
+// decoded as YAML using the same keys as the JSON format, all other files are decoded as JSON
+func readCSVFieldMappingsFile(filePath string) (CSVFieldMappingsJSON, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
 	}
 
-	filePath := fmt.Sprintf("%s/sample_%s.csv", outputPath, strings.ToLower(object.Name))
+	var mappings CSVFieldMappingsJSON
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &mappings)
+	default:
+		err = json.Unmarshal(b, &mappings)
+	}
 
-	file, err := os.Create(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer file.Close()
+	return mappings, nil
+}
 
-	writer := csv.NewWriter(file)
+// mergeCSVFieldMappings returns the mappings of dst with the mappings of src appended per schema,
+// dst is not modified
+func mergeCSVFieldMappings(dst, src CSVFieldMappingsJSON) CSVFieldMappingsJSON {
+	if len(src) == 0 {
+		return dst
+	}
 
-	defer writer.Flush()
+	merged := make(CSVFieldMappingsJSON, len(dst)+len(src))
 
-	if err := writer.Write(headers); err != nil {
-		return err
+	for schemaName, mappings := range dst {
+		merged[schemaName] = slices.Clone(mappings)
 	}
 
-	exampleRow := make([]string, len(headers))
-
-	for i := range object.Fields {
-		exampleRow[i] = fmt.Sprintf("example_%s", strings.ToLower(object.Fields[i]))
+	for schemaName, mappings := range src {
+		merged[schemaName] = append(merged[schemaName], mappings...)
 	}
 
-	for i, mapping := range object.CSVFieldMappings {
-		idx := len(object.Fields) + i
+	return merged
+}
 
-		if mapping.IsSlice {
-			exampleRow[idx] = fmt.Sprintf("example_%s1,example_%s2", strings.ToLower(mapping.CSVColumn), strings.ToLower(mapping.CSVColumn))
-		} else {
-			exampleRow[idx] = fmt.Sprintf("example_%s", strings.ToLower(mapping.CSVColumn))
+// csvFieldMappings returns the CSV field mappings of the mappings file merged with the mappings declared
+// in go. With strict mappings an unreadable file or a mapping that does not match the graphql schema is an
+// error, otherwise the mappings file is skipped when it can not be read and the mappings that do not match
+// the schema are logged
+func (m *Plugin) csvFieldMappings(data codegen.Data) (CSVFieldMappingsJSON, error) {
+	if !m.StrictCSVFieldMappings {
+		mappings := mergeCSVFieldMappings(loadCSVFieldMappings(m.CSVFieldMappingsFile), m.CSVFieldMappings)
+
+		if err := validateCSVFieldMappings(mappings, data); err != nil {
+			log.Warn().Err(err).Str("path", m.CSVFieldMappingsFile).Msg("CSV field mappings do not match the graphql schema")
 		}
+
+		return mappings, nil
 	}
 
-	if err := writer.Write(exampleRow); err != nil {
-		return err
+	var fileMappings CSVFieldMappingsJSON
+
+	if m.CSVFieldMappingsFile != "" {
+		var err error
+
+		fileMappings, err = readCSVFieldMappings(m.CSVFieldMappingsFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidCSVFieldMappings, m.CSVFieldMappingsFile, err)
+		}
 	}
 
-	log.Debug().Str("object", object.Name).Str("path", filePath).Int("customColumns", len(object.CSVFieldMappings)).Msg("sample CSV created")
+	mappings := mergeCSVFieldMappings(fileMappings, m.CSVFieldMappings)
 
-	return nil
-}
+	if err := validateCSVFieldMappings(mappings, data); err != nil {
+		return nil, err
+	}
 
-// csvMutationPrefixes defines the supported prefixes for CSV bulk mutations.
-// Each entry maps a lowercase prefix to its canonical cased version for extraction.
-var csvMutationPrefixes = []struct {
-	lowerPrefix string
-	len         int
-}{
-	{"updatebulkcsv", 13},
-	{"bulkcsvupdate", 13},
-	{"csvbulkupdate", 13},
-	{"createbulkcsv", 13},
-	{"bulkcsvcreate", 13},
-	{"csvbulkcreate", 13},
+	return mappings, nil
 }
 
-// extractObjectNameFromCSVMutation extracts the object name from a CSV bulk mutation name.
-// Uses case-insensitive prefix matching to handle variations like createBulkCSV, CreateBulkCsv, etc.
-// Only matches mutations that start with the expected prefix to avoid false positives with
-// custom resolvers that happen to contain these substrings.
-// Examples: updateBulkCSVControl -> Control, createBulkCSVPolicy -> Policy
-func extractObjectNameFromCSVMutation(mutationName string) string {
-	lowerName := strings.ToLower(mutationName)
+// validateCSVFieldMappings returns an error for each mapping that does not match the graphql schema: the schema
+// must be a type with a create input, the target field must be a field of the create input that is a list
+// when the mapping is a slice, and the column must not be duplicated or collide with a field of the input
+func validateCSVFieldMappings(mappings CSVFieldMappingsJSON, data codegen.Data) error {
+	var errs []error
+
+	for _, schemaName := range slices.Sorted(maps.Keys(mappings)) {
+		if _, ok := data.Schema.Types[schemaName]; !ok {
+			errs = append(errs, fmt.Errorf("%w: schema %s is not in the graphql schema", ErrInvalidCSVFieldMappings, schemaName))
+
+			continue
+		}
+
+		inputTypeName := "Create" + schemaName + "Input"
 
-	for _, p := range csvMutationPrefixes {
-		if strings.HasPrefix(lowerName, p.lowerPrefix) {
-			return mutationName[p.len:]
+		inputType, ok := data.Schema.Types[inputTypeName]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: schema %s has no %s", ErrInvalidCSVFieldMappings, schemaName, inputTypeName))
+
+			continue
 		}
+
+		// the headers of the input fields, compared case-insensitively
+		inputHeaders := map[string]bool{}
+		for _, f := range inputType.Fields {
+			inputHeaders[strings.ToLower(strcase.UpperCamelCase(f.Name))] = true
+		}
+
+		columns := map[string]bool{}
+
+		for _, mapping := range mappings[schemaName] {
+			target := findInputField(inputType, mapping.TargetField)
+
+			switch {
+			case target == nil:
+				errs = append(errs, fmt.Errorf("%w: %s.%s: target field %s is not a field of %s",
+					ErrInvalidCSVFieldMappings, schemaName, mapping.CSVColumn, mapping.TargetField, inputTypeName))
+			case mapping.IsSlice != (target.Type != nil && target.Type.Elem != nil):
+				errs = append(errs, fmt.Errorf("%w: %s.%s: isSlice is %t but target field %s has type %s",
+					ErrInvalidCSVFieldMappings, schemaName, mapping.CSVColumn, mapping.IsSlice, mapping.TargetField, target.Type))
+			}
+
+			column := strings.ToLower(mapping.CSVColumn)
+
+			switch {
+			case inputHeaders[column]:
+				errs = append(errs, fmt.Errorf("%w: %s.%s: column collides with a field of %s",
+					ErrInvalidCSVFieldMappings, schemaName, mapping.CSVColumn, inputTypeName))
+			case columns[column]:
+				errs = append(errs, fmt.Errorf("%w: %s.%s: column is mapped more than once",
+					ErrInvalidCSVFieldMappings, schemaName, mapping.CSVColumn))
+			}
+
+			columns[column] = true
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findInputField returns the field of the input type with the go name, ignoring case, e.g. BlockedGroupIDs
+// for the blockedGroupIDs field, or nil when the input does not have the field
+func findInputField(inputType *ast.Definition, goName string) *ast.FieldDefinition {
+	for _, f := range inputType.Fields {
+		if strings.EqualFold(f.Name, goName) || strings.EqualFold(templates.ToGo(f.Name), goName) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// buildData returns the template data from the plugin options, without any objects
+func (m *Plugin) buildData() BulkResolverBuild {
+	return BulkResolverBuild{
+		Objects:            []Object{},
+		ModelImport:        m.ModelPackage,
+		EntImport:          m.EntGeneratedPackage,
+		GraphQLImport:      m.GraphQLImport,
+		CSVGeneratedImport: m.CSVGeneratedPackage,
+		Imports:            m.RuntimeImports.WithDefaults(),
+		ItemResults:        m.BulkItemResults,
+		CreateChunkSize:    max(m.BulkCreateChunkSize, 0),
+		// the item results create the rows one at a time in the request transaction
+		CommitCreateChunks: m.BulkCreateChunkCommit && m.BulkCreateChunkSize > 0 && !m.BulkItemResults,
+	}
+}
+
+// generateSingleFile generates the bulk resolver code used by the resolvergen plugin for each bulk resolver,
+// this is all done in a single file unless the objects are split into a file per entity
+func (m *Plugin) generateSingleFile(data codegen.Data) error {
+	inputData := m.buildData()
+
+	matcher, err := NewMutationNameMatcher(m.MutationNamePatterns...)
+	if err != nil {
+		return err
+	}
+
+	// the object and operation of each bulk and CSV bulk mutation, matched by the mutation name patterns
+	mutationNames, err := matchMutationNames(matcher.patterns, data.Schema.Mutation.Fields)
+	if err != nil {
+		return err
+	}
+
+	// Build a set of object names that have CSV bulk mutations.
+	csvBulkMutations := make(map[string]bool)
+
+	csvUpsertMutations := make(map[string]bool)
+
+	// the CSV mutations reuse the functions of the bulk mutation of the same operation, so a dry run of
+	// a CSV mutation needs the dry run function of that operation
+	csvDryRunMutations := make(map[bulkOperation]bool)
+
+	// the job variants of the CSV mutations enqueue the rows of the operation, e.g. createBulkCSVControlJob
+	bulkJobMutations := make(map[bulkOperation]bool)
+
+	for _, f := range data.Schema.Mutation.Fields {
+		name, ok := mutationNames[f.Name]
+		if !ok || !name.csv {
+			continue
+		}
+
+		switch name.operation {
+		case "create", "update":
+			if isBulkJobMutation(f) {
+				bulkJobMutations[bulkOperation{
+					object:    strings.TrimSuffix(name.object, bulkJobSuffix),
+					operation: name.operation,
+				}] = true
+
+				continue
+			}
+
+			csvBulkMutations[name.object] = true
+		case "upsert":
+			csvUpsertMutations[name.object] = true
+		default:
+			continue
+		}
+
+		if hasDryRunArgument(f) {
+			csvDryRunMutations[bulkOperation{object: name.object, operation: name.operation}] = true
+		}
+	}
+
+	// only add the model package if the import is not empty
+	if m.ModelPackage != "" {
+		modelPkg := data.Config.Model.Package
+		if modelPkg != "" {
+			modelPkg += "."
+		}
+
+		inputData.ModelPackage = modelPkg
+	}
+
+	if m.CSVOutputPath == "" {
+		m.CSVOutputPath = data.Config.Resolver.Dir() + "/csv"
+	}
+
+	// create the directory if it does not exist
+	if _, err := os.Stat(m.CSVOutputPath); os.IsNotExist(err) && m.DryRunReport == nil {
+		if err := os.MkdirAll(m.CSVOutputPath, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	// Load CSV field mappings from JSON file if configured
+	csvFieldMappings, err := m.csvFieldMappings(data)
+	if err != nil {
+		return err
+	}
+
+	var manifest CSVManifest
+
+	for _, f := range data.Schema.Mutation.Fields {
+		// if the field is a bulk mutation, add it to the list of objects
+		// we skip csv bulk mutations because they will reuse the same functions
+		if name, ok := mutationNames[f.Name]; ok && !name.csv {
+			objectName, operationType := name.object, name.operation
+
+			object := Object{
+				Name:                 objectName,
+				PluralName:           pluralFieldName(objectName),
+				Fields:               getCreateInputFields(objectName, data),
+				AppendFields:         getUpdateAppendFields(objectName, data),
+				OperationType:        operationType,
+				HasCSVUpdateMutation: csvBulkMutations[objectName],
+				CSVFieldMappings:     csvFieldMappings[objectName],
+				Atomic:               m.isAtomic(objectName),
+				DryRun:               hasDryRunArgument(f) || csvDryRunMutations[bulkOperation{object: objectName, operation: operationType}],
+				BulkJob:              bulkJobMutations[bulkOperation{object: objectName, operation: operationType}],
+				SoftDelete:           containsSchema(m.SoftDeleteSchemas, objectName),
+				Archivable:           containsSchema(m.ArchivableSchemas, objectName),
+			}
+
+			// atomic updates must run serially in the request transaction
+			if operationType == "update" && !object.Atomic {
+				object.UpdateWorkers = max(m.BulkUpdateWorkers, 0)
+			}
+
+			// the update sample has the fields of the update input
+			if operationType == "update" {
+				object.SampleFields = getSampleFields("Update"+objectName+"Input", data)
+			} else {
+				object.SampleFields = getSampleFields("Create"+objectName+"Input", data)
+			}
+
+			if m.BulkAuthzPrecheck && (operationType == "create" || operationType == "update") {
+				object.AuthzPrecheck = true
+			}
+
+			if object.AuthzPrecheck && operationType == "create" {
+				object.CreateAuthzChecks = createAuthzChecks(objectName, m.CreateAuthzRelations, data)
+			}
+
+			if operationType == "upsert" {
+				object.HasCSVUpsertMutation = csvUpsertMutations[objectName]
+				object.UpsertKey = m.upsertKey(objectName, data)
+				object.UpsertEdges = upsertEdges(objectName, data)
+			}
+
+			if hasCSVUpload(object) {
+				object.CSVHeaders, object.RequiredCSVHeaders = csvHeaderCheck(object)
+			}
+
+			inputData.Objects = append(inputData.Objects, object)
+
+			if m.DryRunReport != nil {
+				m.DryRunReport.AddBulkObject(bulkObjectReport(object, f.Name, m.CSVOutputPath))
+
+				continue
+			}
+
+			// Generate and write the CSV file only for create, upsert and CSV update operations
+			if hasSampleCSV(object) {
+				if err := generateSampleCSV(object, m.CSVOutputPath); err != nil {
+					return err
+				}
+
+				manifest.Files = append(manifest.Files, sampleManifestFile(object))
+			}
+		}
+	}
+
+	if data.Schema.Query != nil {
+		for _, f := range data.Schema.Query.Fields {
+			objectName := extractObjectNameFromCSVExportQuery(f.Name)
+			if objectName == "" {
+				continue
+			}
+
+			object := Object{
+				Name:             objectName,
+				PluralName:       pluralFieldName(objectName),
+				Fields:           getExportFields(objectName, data),
+				OperationType:    "export",
+				CSVFieldMappings: csvFieldMappings[objectName],
+			}
+
+			object.ExportColumns = exportColumns(object, data)
+
+			inputData.Objects = append(inputData.Objects, object)
+
+			if m.DryRunReport != nil {
+				m.DryRunReport.AddBulkObject(bulkObjectReport(object, f.Name, m.CSVOutputPath))
+			}
+		}
+	}
+
+	if m.DryRunReport != nil {
+		return nil
+	}
+
+	if err := writeCSVManifest(manifest, m.CSVOutputPath); err != nil {
+		return err
+	}
+
+	dir := data.Config.Resolver.Dir()
+
+	if !m.FilePerEntity {
+		// render the bulk resolver template
+		if err := renderBulkFile(data, bulkFile, inputData); err != nil {
+			return err
+		}
+
+		// remove the entity files written before the objects were in a single file
+		return removeStaleBulkFiles(dir, map[string]bool{bulkFile: true})
+	}
+
+	written := map[string]bool{bulkFile: true}
+
+	shared := inputData
+	shared.SharedFile = true
+
+	if err := renderBulkFile(data, bulkFile, shared); err != nil {
+		return err
+	}
+
+	for _, objects := range objectsByEntity(inputData.Objects) {
+		entity := inputData
+		entity.Objects = objects
+		entity.EntityFile = true
+
+		filename := bulkEntityFile(objects[0].Name)
+		written[filename] = true
+
+		if err := renderBulkFile(data, filename, entity); err != nil {
+			return err
+		}
+	}
+
+	return removeStaleBulkFiles(dir, written)
+}
+
+const (
+	// bulkFile is the file of the bulk resolver functions, or of the functions shared by all objects
+	// when the objects are split into a file per entity
+	bulkFile = "bulk.go"
+	// bulkFileNotice is the notice of the generated bulk files, only files with the notice are removed
+	bulkFileNotice = `// THIS CODE IS REGENERATED BY github.com/theopenlane/core/pkg/gqlplugin. DO NOT EDIT.`
+)
+
+// renderBulkFile renders the bulk resolver template to the file in the resolver directory,
+// the file is not written when its content did not change
+func renderBulkFile(data codegen.Data, filename string, inputData BulkResolverBuild) error {
+	return templates.Render(templates.Options{
+		PackageName: data.Config.Resolver.Package,                        // use the resolver package
+		Filename:    filepath.Join(data.Config.Resolver.Dir(), filename), // write to the resolver directory
+		FileNotice:  bulkFileNotice,
+		Data:        inputData,
+		Funcs: template.FuncMap{
+			"toLower":     strings.ToLower,
+			"toSnakeCase": strcase.SnakeCase,
+		},
+		Packages: data.Config.Packages,
+		Template: bulkTemplate,
+	})
+}
+
+// bulkEntityFile returns the name of the file of the bulk resolver functions of the object, e.g. bulk_internal_policy.go
+func bulkEntityFile(objectName string) string {
+	return "bulk_" + strcase.SnakeCase(objectName) + ".go"
+}
+
+// objectsByEntity groups the objects by name, in the order of their first object
+func objectsByEntity(objects []Object) [][]Object {
+	var groups [][]Object
+
+	index := map[string]int{}
+
+	for _, object := range objects {
+		i, ok := index[object.Name]
+		if !ok {
+			i = len(groups)
+			index[object.Name] = i
+
+			groups = append(groups, nil)
+		}
+
+		groups[i] = append(groups[i], object)
+	}
+
+	return groups
+}
+
+// removeStaleBulkFiles removes the generated bulk_<entity>.go files in the directory that were not written,
+// e.g. the file of a schema that was removed, files without the generated notice are left in place
+func removeStaleBulkFiles(dir string, written map[string]bool) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "bulk_*.go"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if written[name] || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(string(content), bulkFileNotice) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isAtomic returns true when the bulk operations of the object should be all-or-nothing
+func (m *Plugin) isAtomic(objectName string) bool {
+	return m.AtomicBulkOperations || containsSchema(m.AtomicBulkObjects, objectName)
+}
+
+// containsSchema returns true when the object is in the configured schemas, compared case-insensitively
+func containsSchema(schemas []string, objectName string) bool {
+	return slices.ContainsFunc(schemas, func(s string) bool {
+		return strings.EqualFold(s, objectName)
+	})
+}
+
+// upsertKey returns the natural key of the bulk upsert of the object, nil when no key is configured or
+// the configured field is not on the create input, in which case the rows are only matched by id
+func (m *Plugin) upsertKey(objectName string, data codegen.Data) *UpsertKey {
+	name, ok := m.UpsertKeys[objectName]
+	if !ok || name == "" {
+		return nil
+	}
+
+	inputType, ok := data.Schema.Types["Create"+objectName+"Input"]
+	if !ok {
+		return nil
+	}
+
+	field := inputType.Fields.ForName(name)
+	if field == nil {
+		log.Warn().Str("object", objectName).Str("field", name).Msg("upsert key is not a field of the create input, rows are only matched by id")
+
+		return nil
+	}
+
+	return &UpsertKey{
+		Name:     name,
+		GoName:   templates.ToGo(name),
+		Nillable: !field.Type.NonNull,
+	}
+}
+
+// upsertEdges returns the lists of ids of the create input of the object with the field of the update input
+// that clears their edge, e.g. programIDs is cleared by clearPrograms. A list without a clear field is
+// skipped with a warning, the bulk upsert adds its ids to the edge of the updated objects
+func upsertEdges(objectName string, data codegen.Data) (edges []UpsertEdge) {
+	createType, ok := data.Schema.Types["Create"+objectName+"Input"]
+	if !ok {
+		return nil
+	}
+
+	updateType, ok := data.Schema.Types["Update"+objectName+"Input"]
+	if !ok {
+		return nil
+	}
+
+	client := pluralize.NewClient()
+
+	for _, f := range createType.Fields {
+		if f.Type == nil || f.Type.Elem == nil || f.Type.Elem.Name() != "ID" {
+			continue
+		}
+
+		stem, ok := strings.CutSuffix(f.Name, "IDs")
+		if !ok {
+			continue
+		}
+
+		// the update input names the clear field after the edge and the list after the singular of the edge
+		clearField := slices.IndexFunc(updateType.Fields, func(u *ast.FieldDefinition) bool {
+			edge, ok := strings.CutPrefix(u.Name, "clear")
+
+			return ok && u.Type.Name() == "Boolean" && strings.EqualFold(client.Singular(edge), stem)
+		})
+		if clearField < 0 {
+			log.Warn().Str("object", objectName).Str("field", f.Name).Msg("update input has no clear field for the ids of the create input, the bulk upsert adds them to the edge of the updated objects")
+
+			continue
+		}
+
+		edges = append(edges, UpsertEdge{
+			Name:   f.Name,
+			GoName: templates.ToGo(f.Name),
+			Clear:  templates.ToGo(updateType.Fields[clearField].Name),
+		})
+	}
+
+	return edges
+}
+
+const (
+	// ownerIDField is the field of the create input with the id of the organization that owns the object
+	ownerIDField = "ownerID"
+	// ownerObjectType is the authorization object type of the owner id
+	ownerObjectType = "organization"
+)
+
+// createAuthzChecks returns the owner and parent ids of the create input of the object that are checked before
+// a bulk create. The owner id requires the create relation of the object on the organization, e.g. can_create_control.
+// Any other id field is only checked with a relation in the relations, on the object the field is named after,
+// e.g. controlID is a control. Lists of ids are not checked
+func createAuthzChecks(objectName string, relations map[string]string, data codegen.Data) (checks []CreateAuthzCheck) {
+	inputType, ok := data.Schema.Types["Create"+objectName+"Input"]
+	if !ok {
+		return nil
+	}
+
+	for _, f := range inputType.Fields {
+		if f.Type == nil || f.Type.Elem != nil || f.Type.Name() != "ID" {
+			continue
+		}
+
+		relation, hasRelation := relations[f.Name]
+
+		check := CreateAuthzCheck{
+			Name:       f.Name,
+			GoName:     templates.ToGo(f.Name),
+			ObjectType: ownerObjectType,
+			Relation:   relation,
+			Nillable:   !f.Type.NonNull,
+		}
+
+		switch {
+		case f.Name == ownerIDField:
+			if !hasRelation {
+				check.Relation = "can_create_" + strcase.SnakeCase(objectName)
+			}
+		case hasRelation:
+			parent, _ := strings.CutSuffix(f.Name, "ID")
+
+			def, ok := data.Schema.Types[templates.UcFirst(parent)]
+			if !ok || def.Kind != ast.Object {
+				log.Warn().Str("object", objectName).Str("field", f.Name).Msg("create authz relation is set on a field that is not named after an object, the field is not checked")
+
+				continue
+			}
+
+			check.ObjectType = strcase.SnakeCase(def.Name)
+		default:
+			continue
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// getCreateInputFields returns the list of fields available in the Create<object>Input
+func getCreateInputFields(objectName string, data codegen.Data) (inputFields []string) {
+	inputTypeName := "Create" + objectName + "Input"
+	if inputType, ok := data.Schema.Types[inputTypeName]; ok {
+		for _, f := range inputType.Fields {
+			inputFields = append(inputFields, strcase.UpperCamelCase(f.Name))
+		}
+	}
+
+	return inputFields
+}
+
+// getExportFields returns the fields of the update input that set a value, so every column of the CSV export
+// is accepted by the CSV bulk update. The clear, append, add and remove fields change the value of the object
+// and are not exported. Objects without an update input export the fields of the create input
+func getExportFields(objectName string, data codegen.Data) (exportFields []string) {
+	inputType, ok := data.Schema.Types["Update"+objectName+"Input"]
+	if !ok {
+		return getCreateInputFields(objectName, data)
+	}
+
+	for _, f := range inputType.Fields {
+		if isUpdateOperationField(f.Name) {
+			continue
+		}
+
+		exportFields = append(exportFields, strcase.UpperCamelCase(f.Name))
+	}
+
+	return exportFields
+}
+
+// updateOperationPrefixes are the prefixes of the update input fields that change the value of a field
+// instead of setting it, e.g. clearTags or addProgramIDs
+var updateOperationPrefixes = []string{"clear", "append", "add", "remove"}
+
+// isUpdateOperationField returns true when the update input field changes the value of another field,
+// e.g. clearTags, the prefix must be followed by the upper case name of the field so address is not matched
+func isUpdateOperationField(name string) bool {
+	for _, prefix := range updateOperationPrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if ok && rest != "" && rest[0] >= 'A' && rest[0] <= 'Z' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSampleFields returns the fields of the input type with an example value for the type of each field
+func getSampleFields(inputTypeName string, data codegen.Data) (sampleFields []SampleField) {
+	if inputType, ok := data.Schema.Types[inputTypeName]; ok {
+		for _, f := range inputType.Fields {
+			field := SampleField{
+				Name:    strcase.UpperCamelCase(f.Name),
+				Example: sampleValue(f, data),
+			}
+
+			if f.Type != nil {
+				field.Type = f.Type.String()
+				field.Required = f.Type.NonNull
+
+				if def, ok := data.Schema.Types[f.Type.Name()]; ok && def.Kind == ast.Enum {
+					for _, v := range def.EnumValues {
+						field.EnumValues = append(field.EnumValues, v.Name)
+					}
+				}
+			}
+
+			sampleFields = append(sampleFields, field)
+		}
+	}
+
+	return sampleFields
+}
+
+// sampleTimestamp is the example value of time fields, it is fixed so the sample CSVs are not changed on every generation
+const sampleTimestamp = "2025-01-01T00:00:00Z"
+
+// sampleValue returns an example value of the input field that is valid for its type, enums use the first value
+// of the enum and lists are written as a JSON array. References to other objects and the clear fields of the
+// update input are left empty, an example id would not exist when the sample is uploaded. Required fields
+// without an example are also left empty, their header is marked as required in the sample CSV
+func sampleValue(field *ast.FieldDefinition, data codegen.Data) string {
+	if field.Type == nil {
+		return sampleString(field.Name)
+	}
+
+	// setting the clear fields of the update input in the example would clear the values of the object
+	if strings.HasPrefix(field.Name, "clear") {
+		return ""
+	}
+
+	example := sampleExample(field.Name, field.Type, data)
+
+	switch {
+	case example == nil:
+		return ""
+	case field.Type.Elem != nil:
+		example = []any{example}
+	}
+
+	if s, ok := example.(string); ok {
+		return s
+	}
+
+	b, err := json.Marshal(example)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+// sampleExample returns the example value of a single element of the type, or nil when there is no valid example
+func sampleExample(fieldName string, t *ast.Type, data codegen.Data) any {
+	if def, ok := data.Schema.Types[t.Name()]; ok && def.Kind == ast.Enum {
+		if len(def.EnumValues) == 0 {
+			return nil
+		}
+
+		return def.EnumValues[0].Name
+	}
+
+	switch t.Name() {
+	case "String":
+		return sampleString(fieldName)
+	case "Boolean":
+		return true
+	case "Int":
+		return 1
+	case "Float":
+		return 1.5
+	case "Time", "DateTime":
+		return sampleTimestamp
+	case "Map", "JSON":
+		return map[string]any{}
+	default:
+		// ids reference other objects and input objects and custom scalars have no generic example
+		return nil
+	}
+}
+
+// sampleString returns the example value of a string field, e.g. example_refcode
+func sampleString(fieldName string) string {
+	return fmt.Sprintf("example_%s", strings.ToLower(strcase.UpperCamelCase(fieldName)))
+}
+
+// getUpdateAppendFields returns the list of fields that are appendable in the bulk update mutation
+func getUpdateAppendFields(objectName string, data codegen.Data) (appendFields []string) {
+	inputTypeName := "Update" + objectName + "Input"
+	if inputType, ok := data.Schema.Types[inputTypeName]; ok {
+		for _, f := range inputType.Fields {
+			if strings.Contains(f.Name, "append") {
+				appendFields = append(appendFields, templates.ToGo(f.Name))
+			}
+		}
+	}
+
+	return appendFields
+}
+
+// exportColumns returns the columns of the CSV export of the object, the id followed by the export fields and the
+// custom columns so an export can be edited and uploaded to the CSV bulk update. Each column is filled from the scalar
+// or enum field of the object with the same name and the custom columns from the field they map to, the other
+// columns, such as the ids of edges, are left empty
+func exportColumns(object Object, data codegen.Data) []ExportColumn {
+	// go names of the fields of the object, keyed by both the sample CSV header and the go name
+	goNames := map[string]string{}
+
+	if def, ok := data.Schema.Types[object.Name]; ok {
+		for _, f := range def.Fields {
+			fieldType, ok := data.Schema.Types[f.Type.Name()]
+			if !ok || (fieldType.Kind != ast.Scalar && fieldType.Kind != ast.Enum) {
+				continue
+			}
+
+			goName := templates.ToGo(f.Name)
+			goNames[strcase.UpperCamelCase(f.Name)] = goName
+			goNames[goName] = goName
+		}
+	}
+
+	columns := []ExportColumn{{Header: idColumn, GoName: "ID"}}
+	seen := map[string]bool{idColumn: true}
+
+	add := func(header, field string) {
+		if seen[header] {
+			return
+		}
+
+		seen[header] = true
+
+		columns = append(columns, ExportColumn{Header: header, GoName: goNames[field]})
+	}
+
+	for _, field := range object.Fields {
+		add(field, field)
+	}
+
+	for _, mapping := range object.CSVFieldMappings {
+		add(mapping.CSVColumn, mapping.TargetField)
+	}
+
+	return columns
+}
+
+// bulkObjectReport returns the dry run report entry for the bulk object
+func bulkObjectReport(object Object, mutationName, outputPath string) genreport.BulkObject {
+	entry := genreport.BulkObject{
+		Object:               object.Name,
+		Mutation:             mutationName,
+		Operation:            object.OperationType,
+		Fields:               object.Fields,
+		HasCSVUpdateMutation: object.HasCSVUpdateMutation,
+		Atomic:               object.Atomic && object.OperationType != "create",
+		UpdateWorkers:        object.UpdateWorkers,
+		DryRun:               object.DryRun,
+		BulkJob:              object.BulkJob,
+		SoftDelete:           object.SoftDelete && object.OperationType == "delete",
+	}
+
+	for _, mapping := range object.CSVFieldMappings {
+		entry.CSVColumns = append(entry.CSVColumns, mapping.CSVColumn)
+	}
+
+	for _, check := range object.CreateAuthzChecks {
+		entry.AuthzFields = append(entry.AuthzFields, check.Name)
+	}
+
+	if hasSampleCSV(object) {
+		entry.RequiredFields = requiredFields(object)
+		entry.SampleCSV = sampleCSVPath(object, outputPath)
+	}
+
+	if object.UpsertKey != nil {
+		entry.UpsertKey = object.UpsertKey.Name
+	}
+
+	return entry
+}
+
+// hasSampleCSV returns true when a sample CSV is written for the bulk operation of the object,
+// the update sample is only written for objects with a CSV bulk update mutation
+func hasSampleCSV(object Object) bool {
+	switch object.OperationType {
+	case "create", "upsert":
+		return true
+	case "update":
+		return object.HasCSVUpdateMutation
+	default:
+		return false
+	}
+}
+
+// hasCSVUpload returns true when the bulk operation of the object has a CSV upload, the CSV bulk create
+// reuses the bulk create of the object
+func hasCSVUpload(object Object) bool {
+	switch object.OperationType {
+	case "create":
+		return true
+	case "update":
+		return object.HasCSVUpdateMutation
+	case "upsert":
+		return object.HasCSVUpsertMutation
+	default:
+		return false
+	}
+}
+
+// csvHeaderCheck returns the known and required headers of the CSV upload of the object, the same columns
+// as the sample CSV. A required field can also be set with a custom column that maps to it
+func csvHeaderCheck(object Object) (headers []string, required []RequiredCSVHeader) {
+	columns := sampleColumns(object)
+
+	for _, column := range columns {
+		headers = append(headers, column.Header)
+	}
+
+	for _, column := range columns {
+		if !column.Required {
+			continue
+		}
+
+		header := RequiredCSVHeader{Header: column.Header}
+
+		for _, c := range columns {
+			if c.MappedTo != "" && strings.EqualFold(c.MappedTo, column.Header) {
+				header.Alternatives = append(header.Alternatives, c.Header)
+			}
+		}
+
+		required = append(required, header)
+	}
+
+	return headers, required
+}
+
+// requiredFields returns the headers of the required columns of the sample CSV of the object
+func requiredFields(object Object) (required []string) {
+	for _, column := range sampleColumns(object) {
+		if column.Required {
+			required = append(required, column.Header)
+		}
+	}
+
+	return required
+}
+
+// sampleCSVPath returns the path the sample CSV for the object is written to
+func sampleCSVPath(object Object, outputPath string) string {
+	return fmt.Sprintf("%s/%s", outputPath, sampleCSVFile(object))
+}
+
+// sampleCSVFile returns the file name of the sample CSV for the object
+func sampleCSVFile(object Object) string {
+	switch object.OperationType {
+	case "upsert", "update":
+		return fmt.Sprintf("sample_%s_%s.csv", strings.ToLower(object.Name), object.OperationType)
+	default:
+		return fmt.Sprintf("sample_%s.csv", strings.ToLower(object.Name))
+	}
+}
+
+// idColumn is the column with the id of the object in the upsert and update sample CSVs and the CSV exports
+const idColumn = "ID"
+
+// csvManifestFile is the name of the manifest written next to the sample CSVs
+const csvManifestFile = "csv_manifest.json"
+
+// csvManifestPerm is the permission of the manifest, it is read by the frontend so it is readable by everyone
+// like the resolvers written by gqlgen
+const csvManifestPerm = 0o644
+
+// CSVManifest describes the headers of the sample CSVs so upload templates can be rendered from it
+type CSVManifest struct {
+	// Files are the sample CSVs, in the order of the bulk mutations in the schema
+	Files []CSVManifestFile `json:"files"`
+}
+
+// CSVManifestFile describes the columns of a single sample CSV
+type CSVManifestFile struct {
+	// Object is the schema name, e.g. Control
+	Object string `json:"object"`
+	// Operation is the bulk operation the CSV is uploaded to, e.g. create, update or upsert
+	Operation string `json:"operation"`
+	// File is the file name of the sample CSV, e.g. sample_control.csv
+	File string `json:"file"`
+	// Columns are the columns of the CSV, in order
+	Columns []CSVManifestColumn `json:"columns"`
+}
+
+// CSVManifestColumn describes a column of a sample CSV
+type CSVManifestColumn struct {
+	// Header is the CSV header of the column, e.g. RefCode
+	Header string `json:"header"`
+	// Type is the graphql type of the input field, e.g. String! or [ID!]
+	Type string `json:"type,omitempty"`
+	// Required indicates each row must have a value for the column
+	Required bool `json:"required"`
+	// EnumValues are the allowed values of enum columns
+	EnumValues []string `json:"enumValues,omitempty"`
+	// MappedTo is the field a custom column is resolved to by the upload, e.g. AssignedToUserID
+	MappedTo string `json:"mappedTo,omitempty"`
+	// Example is the value of the column in the example row
+	Example string `json:"example,omitempty"`
+}
+
+// sampleColumns returns the columns of the sample CSV of the object: the id for the upsert and update samples,
+// followed by the input fields and the custom CSV column mappings from entx annotations
+func sampleColumns(object Object) []CSVManifestColumn {
+	columns := make([]CSVManifestColumn, 0, len(object.Fields)+len(object.CSVFieldMappings)+1)
+
+	switch object.OperationType {
+	case "upsert":
+		// the id is left empty for new rows
+		columns = append(columns, CSVManifestColumn{Header: idColumn, Type: "ID"})
+	case "update":
+		// the id of an existing object must be filled in, an example id would not exist
+		columns = append(columns, CSVManifestColumn{Header: idColumn, Type: "ID!", Required: true})
+	}
+
+	fields := object.SampleFields
+	if len(fields) == 0 {
+		for _, f := range object.Fields {
+			fields = append(fields, SampleField{Name: f, Example: sampleString(f)})
+		}
+	}
+
+	for _, f := range fields {
+		columns = append(columns, CSVManifestColumn{
+			Header:     f.Name,
+			Type:       f.Type,
+			Required:   f.Required,
+			EnumValues: f.EnumValues,
+			Example:    f.Example,
+		})
+	}
+
+	for _, mapping := range object.CSVFieldMappings {
+		column := CSVManifestColumn{
+			Header:   mapping.CSVColumn,
+			Type:     "String",
+			MappedTo: mapping.TargetField,
+			Example:  fmt.Sprintf("example_%s", strings.ToLower(mapping.CSVColumn)),
+		}
+
+		// custom list columns use the same JSON array format as the list fields
+		if mapping.IsSlice {
+			column.Type = "[String!]"
+			column.Example = fmt.Sprintf(`["example_%s1","example_%s2"]`, strings.ToLower(mapping.CSVColumn), strings.ToLower(mapping.CSVColumn))
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// sampleManifestFile returns the manifest entry of the sample CSV of the object
+func sampleManifestFile(object Object) CSVManifestFile {
+	return CSVManifestFile{
+		Object:    object.Name,
+		Operation: object.OperationType,
+		File:      sampleCSVFile(object),
+		Columns:   sampleColumns(object),
+	}
+}
+
+// generateSampleCSV generates a sample CSV file for the given object.
+// It includes both standard input fields and custom CSV column mappings from entx annotations,
+// the upsert and update sample CSVs start with an ID column. The headers of the required columns
+// end with graphutils.RequiredCSVHeaderMarker, which the CSV upload removes
+func generateSampleCSV(object Object, outputPath string) error {
+	columns := sampleColumns(object)
+
+	headers := make([]string, 0, len(columns))
+	exampleRow := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		header := column.Header
+		if column.Required {
+			header += graphutils.RequiredCSVHeaderMarker
+		}
+
+		headers = append(headers, header)
+		exampleRow = append(exampleRow, column.Example)
+	}
+
+	filePath := sampleCSVPath(object, outputPath)
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	defer writer.Flush()
+
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	if err := writer.Write(exampleRow); err != nil {
+		return err
+	}
+
+	log.Debug().Str("object", object.Name).Str("path", filePath).Int("customColumns", len(object.CSVFieldMappings)).
+		Strs("requiredColumns", requiredFields(object)).Msg("sample CSV created")
+
+	return nil
+}
+
+// writeCSVManifest writes the manifest of the sample CSVs to the output path
+func writeCSVManifest(manifest CSVManifest, outputPath string) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fmt.Sprintf("%s/%s", outputPath, csvManifestFile), append(b, '\n'), csvManifestPerm) // nolint:gosec
+}
+
+// bulkOperation is a bulk operation of an object, e.g. the update of Control
+type bulkOperation struct {
+	object    string
+	operation string
+}
+
+// dryRunArgument is the argument of the bulk and CSV mutations that validates the rows without saving them
+const dryRunArgument = "dryRun"
+
+// hasDryRunArgument returns true when the mutation has a dryRun argument
+func hasDryRunArgument(f *ast.FieldDefinition) bool {
+	return f.Arguments.ForName(dryRunArgument) != nil
+}
+
+const (
+	// bulkJobType is the return type of the job variants of the CSV bulk mutations
+	bulkJobType = "BulkJob"
+	// bulkJobSuffix is the suffix of the job variants of the CSV bulk mutations, e.g. createBulkCSVControlJob
+	bulkJobSuffix = "Job"
+)
+
+// isBulkJobMutation returns true when the CSV mutation is a job variant that returns the status of a bulk job
+// instead of the payload, only create and update are run as jobs
+func isBulkJobMutation(f *ast.FieldDefinition) bool {
+	return f.Type != nil && f.Type.Name() == bulkJobType && strings.HasSuffix(f.Name, bulkJobSuffix)
+}
+
+// extractObjectNameFromCSVExportQuery extracts the object name from a CSV export query name, matching the
+// export prefix and CSV suffix case-insensitively.
+// Examples: exportControlCSV -> Control, ExportPolicyCsv -> Policy
+func extractObjectNameFromCSVExportQuery(queryName string) string {
+	const (
+		prefix = "export"
+		suffix = "csv"
+	)
+
+	lowerName := strings.ToLower(queryName)
+	if len(lowerName) <= len(prefix)+len(suffix) || !strings.HasPrefix(lowerName, prefix) || !strings.HasSuffix(lowerName, suffix) {
+		return ""
 	}
 
-	return ""
+	return queryName[len(prefix) : len(queryName)-len(suffix)]
 }
 
 // pluralFieldName makes go-pluralize and gqlgen return same values for certain edgecases
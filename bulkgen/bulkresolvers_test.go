@@ -1,92 +1,21 @@
 package bulkgen
 
 import (
+	"encoding/json"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
 	"github.com/stretchr/testify/assert"
-)
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
 
-func TestExtractObjectNameFromCSVMutation(t *testing.T) {
-	testCases := []struct {
-		name         string
-		mutationName string
-		expected     string
-	}{
-		{
-			name:         "updateBulkCSV prefix",
-			mutationName: "updateBulkCSVControl",
-			expected:     "Control",
-		},
-		{
-			name:         "updateBulkCSV prefix with longer name",
-			mutationName: "updateBulkCSVRiskAssessment",
-			expected:     "RiskAssessment",
-		},
-		{
-			name:         "bulkCSVUpdate prefix",
-			mutationName: "bulkCSVUpdatePolicy",
-			expected:     "Policy",
-		},
-		{
-			name:         "csvBulkUpdate prefix",
-			mutationName: "csvBulkUpdateUser",
-			expected:     "User",
-		},
-		{
-			name:         "createBulkCSV prefix",
-			mutationName: "createBulkCSVControl",
-			expected:     "Control",
-		},
-		{
-			name:         "bulkCSVCreate prefix",
-			mutationName: "bulkCSVCreatePolicy",
-			expected:     "Policy",
-		},
-		{
-			name:         "csvBulkCreate prefix",
-			mutationName: "csvBulkCreateUser",
-			expected:     "User",
-		},
-		{
-			name:         "case insensitive matching",
-			mutationName: "updatebulkcsvcontrol",
-			expected:     "control",
-		},
-		{
-			name:         "mixed case CSV variation",
-			mutationName: "CreateBulkCsvControl",
-			expected:     "Control",
-		},
-		{
-			name:         "no matching prefix",
-			mutationName: "createUser",
-			expected:     "",
-		},
-		{
-			name:         "bulk but not CSV",
-			mutationName: "createBulkControl",
-			expected:     "",
-		},
-		{
-			name:         "empty string",
-			mutationName: "",
-			expected:     "",
-		},
-		{
-			name:         "pattern in middle of name returns empty",
-			mutationName: "doSomeUpdateBulkCSVProcessing",
-			expected:     "",
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			result := extractObjectNameFromCSVMutation(tc.mutationName)
-			assert.Equal(t, tc.expected, result)
-		})
-	}
-}
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+)
 
 func TestWithCSVGeneratedPackage(t *testing.T) {
 	testCases := []struct {
@@ -149,6 +78,161 @@ func TestNewWithOptions(t *testing.T) {
 	})
 }
 
+func TestIsAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Options
+		object   string
+		expected bool
+	}{
+		{
+			name:     "not configured",
+			object:   "Control",
+			expected: false,
+		},
+		{
+			name:     "all objects",
+			opts:     []Options{WithAtomicBulkOperations()},
+			object:   "Control",
+			expected: true,
+		},
+		{
+			name:     "configured object",
+			opts:     []Options{WithAtomicBulkObjects("control", "Policy")},
+			object:   "Control",
+			expected: true,
+		},
+		{
+			name:     "other object",
+			opts:     []Options{WithAtomicBulkObjects("Policy")},
+			object:   "Control",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := NewWithOptions(tt.opts...)
+			assert.Equal(t, tt.expected, plugin.isAtomic(tt.object))
+		})
+	}
+}
+
+func TestContainsSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		schemas  []string
+		object   string
+		expected bool
+	}{
+		{
+			name:     "not configured",
+			object:   "Control",
+			expected: false,
+		},
+		{
+			name:     "configured in another case",
+			schemas:  []string{"control", "Policy"},
+			object:   "Control",
+			expected: true,
+		},
+		{
+			name:     "other object",
+			schemas:  []string{"Policy"},
+			object:   "Control",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, containsSchema(tt.schemas, tt.object))
+		})
+	}
+}
+
+func TestCreateChunkOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Options
+		expectedSize   int
+		expectedCommit bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:         "chunk size",
+			opts:         []Options{WithBulkCreateChunkSize(500)},
+			expectedSize: 500,
+		},
+		{
+			name:           "chunk commit",
+			opts:           []Options{WithBulkCreateChunkSize(500), WithBulkCreateChunkCommit()},
+			expectedSize:   500,
+			expectedCommit: true,
+		},
+		{
+			name: "chunk commit without chunk size",
+			opts: []Options{WithBulkCreateChunkCommit()},
+		},
+		{
+			name:         "chunk commit with item results",
+			opts:         []Options{WithBulkCreateChunkSize(500), WithBulkCreateChunkCommit(), WithBulkItemResults()},
+			expectedSize: 500,
+		},
+		{
+			name: "negative chunk size",
+			opts: []Options{WithBulkCreateChunkSize(-1), WithBulkCreateChunkCommit()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := NewWithOptions(tt.opts...)
+
+			data := plugin.buildData()
+			assert.Equal(t, tt.expectedSize, data.CreateChunkSize)
+			assert.Equal(t, tt.expectedCommit, data.CommitCreateChunks)
+		})
+	}
+}
+
+func TestGenerateCodeDryRunParallelUpdate(t *testing.T) {
+	report := genreport.New()
+
+	p := NewWithOptions(WithDryRun(report), WithParallelBulkUpdate(8), WithAtomicBulkObjects("Policy"))
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: t.TempDir()},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{
+				Name: "Mutation",
+				Fields: ast.FieldList{
+					{Name: "updateBulkTask"},
+					{Name: "updateBulkPolicy"},
+					{Name: "deleteBulkTask"},
+				},
+			},
+			Types: map[string]*ast.Definition{},
+		},
+	}
+
+	require.NoError(t, p.GenerateCode(data))
+
+	require.Len(t, report.Bulk, 3)
+
+	workers := map[string]int{}
+	for _, b := range report.Bulk {
+		workers[b.Mutation] = b.UpdateWorkers
+	}
+
+	// atomic objects are updated serially in the request transaction
+	assert.Equal(t, map[string]int{"updateBulkTask": 8, "updateBulkPolicy": 0, "deleteBulkTask": 0}, workers)
+}
+
 func TestPluginName(t *testing.T) {
 	plugin := New()
 	assert.Equal(t, "bulkgen", plugin.Name())
@@ -256,7 +340,7 @@ func TestGenerateSampleCSVWithCustomColumns(t *testing.T) {
 	assert.Contains(t, contentStr, "Name,Description,Status,AssignedToUserEmail,BlockedGroupNames")
 	assert.Contains(t, contentStr, "example_name,example_description,example_status")
 	assert.Contains(t, contentStr, "example_assignedtouseremail")
-	assert.Contains(t, contentStr, "example_blockedgroupnames1,example_blockedgroupnames2")
+	assert.Contains(t, contentStr, `"[""example_blockedgroupnames1"",""example_blockedgroupnames2""]"`)
 }
 
 func TestPluralFieldName(t *testing.T) {
@@ -336,3 +420,1173 @@ func TestGenerateSampleCSVWithoutCustomColumns(t *testing.T) {
 	assert.Contains(t, contentStr, "Name,Value")
 	assert.Contains(t, contentStr, "example_name,example_value")
 }
+
+func TestGenerateCodeDryRun(t *testing.T) {
+	report := genreport.New()
+	resolverDir := t.TempDir()
+
+	p := NewWithOptions(WithDryRun(report))
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: resolverDir},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{
+				Name: "Mutation",
+				Fields: ast.FieldList{
+					{Name: "createBulkTask"},
+					{Name: "createBulkCSVTask"},
+					{Name: "updateBulkCSVTask"},
+					{Name: "deleteBulkTask"},
+				},
+			},
+			Types: map[string]*ast.Definition{
+				"CreateTaskInput": {Name: "CreateTaskInput", Fields: ast.FieldList{{Name: "title"}}},
+			},
+		},
+	}
+
+	require.NoError(t, p.GenerateCode(data))
+
+	require.Len(t, report.Bulk, 2)
+	assert.Equal(t, genreport.BulkObject{
+		Object:               "Task",
+		Mutation:             "createBulkTask",
+		Operation:            "create",
+		Fields:               []string{"Title"},
+		HasCSVUpdateMutation: true,
+		SampleCSV:            resolverDir + "/csv/sample_task.csv",
+	}, report.Bulk[0])
+	assert.Equal(t, "delete", report.Bulk[1].Operation)
+	assert.Empty(t, report.Bulk[1].SampleCSV)
+
+	// nothing is written to the resolver directory
+	entries, err := os.ReadDir(resolverDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestGenerateCodeDryRunUpsert(t *testing.T) {
+	report := genreport.New()
+	resolverDir := t.TempDir()
+
+	p := NewWithOptions(WithDryRun(report), WithUpsertKeys(map[string]string{"Control": "refCode", "Policy": "code"}))
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: resolverDir},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{
+				Name: "Mutation",
+				Fields: ast.FieldList{
+					{Name: "upsertBulkControl"},
+					{Name: "createOrUpdateBulkCSVControl"},
+					{Name: "bulkUpsertPolicy"},
+				},
+			},
+			Types: map[string]*ast.Definition{
+				"CreateControlInput": {Name: "CreateControlInput", Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "title", Type: ast.NamedType("String", nil)},
+				}},
+				"CreatePolicyInput": {Name: "CreatePolicyInput", Fields: ast.FieldList{
+					{Name: "name", Type: ast.NamedType("String", nil)},
+				}},
+			},
+		},
+	}
+
+	require.NoError(t, p.GenerateCode(data))
+
+	require.Len(t, report.Bulk, 2)
+	assert.Equal(t, genreport.BulkObject{
+		Object:         "Control",
+		Mutation:       "upsertBulkControl",
+		Operation:      "upsert",
+		Fields:         []string{"RefCode", "Title"},
+		RequiredFields: []string{"RefCode"},
+		SampleCSV:      resolverDir + "/csv/sample_control_upsert.csv",
+		UpsertKey:      "refCode",
+	}, report.Bulk[0])
+
+	// the configured key is not on the create input, so the rows are only matched by id
+	assert.Equal(t, "Policy", report.Bulk[1].Object)
+	assert.Empty(t, report.Bulk[1].UpsertKey)
+}
+
+func TestUpsertKey(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"CreateControlInput": {Name: "CreateControlInput", Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NamedType("String", nil)},
+					{Name: "displayID", Type: ast.NonNullNamedType("String", nil)},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		expected *UpsertKey
+	}{
+		{
+			name:     "nillable key",
+			key:      "refCode",
+			expected: &UpsertKey{Name: "refCode", GoName: "RefCode", Nillable: true},
+		},
+		{
+			name:     "required key",
+			key:      "displayID",
+			expected: &UpsertKey{Name: "displayID", GoName: "DisplayID"},
+		},
+		{
+			name: "unknown field",
+			key:  "code",
+		},
+		{
+			name: "no key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewWithOptions(WithUpsertKeys(map[string]string{"Control": tt.key}))
+			assert.Equal(t, tt.expected, p.upsertKey("Control", data))
+		})
+	}
+}
+
+func TestUpsertEdges(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"CreateControlInput": {Name: "CreateControlInput", Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "ownerID", Type: ast.NamedType("ID", nil)},
+					{Name: "programIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "blockedGroupIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "tagIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "tags", Type: ast.ListType(ast.NonNullNamedType("String", nil), nil)},
+				}},
+				"UpdateControlInput": {Name: "UpdateControlInput", Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NamedType("String", nil)},
+					{Name: "clearOwner", Type: ast.NamedType("Boolean", nil)},
+					{Name: "addProgramIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "removeProgramIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "clearPrograms", Type: ast.NamedType("Boolean", nil)},
+					{Name: "addBlockedGroupIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "clearBlockedGroups", Type: ast.NamedType("Boolean", nil)},
+					{Name: "addTagIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+				}},
+				"CreateNoteInput": {Name: "CreateNoteInput", Fields: ast.FieldList{
+					{Name: "taskIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		object   string
+		expected []UpsertEdge
+	}{
+		{
+			name:   "lists of ids with a clear field",
+			object: "Control",
+			expected: []UpsertEdge{
+				{Name: "programIDs", GoName: "ProgramIDs", Clear: "ClearPrograms"},
+				{Name: "blockedGroupIDs", GoName: "BlockedGroupIDs", Clear: "ClearBlockedGroups"},
+			},
+		},
+		{
+			name:   "no update input",
+			object: "Note",
+		},
+		{
+			name:   "no create input",
+			object: "Task",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, upsertEdges(tt.object, data))
+		})
+	}
+}
+
+func TestCreateAuthzChecks(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"Control":        {Name: "Control", Kind: ast.Object},
+				"InternalPolicy": {Name: "InternalPolicy", Kind: ast.Object},
+				"ControlStatus":  {Name: "ControlStatus", Kind: ast.Enum},
+				"CreateSubcontrolInput": {Name: "CreateSubcontrolInput", Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "ownerID", Type: ast.NamedType("ID", nil)},
+					{Name: "controlID", Type: ast.NonNullNamedType("ID", nil)},
+					{Name: "internalPolicyID", Type: ast.NamedType("ID", nil)},
+					{Name: "programIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "assignedToUserID", Type: ast.NamedType("ID", nil)},
+					{Name: "controlStatusID", Type: ast.NamedType("ID", nil)},
+					{Name: "externalID", Type: ast.NamedType("String", nil)},
+				}},
+				"CreateNoteInput": {Name: "CreateNoteInput", Fields: ast.FieldList{
+					{Name: "text", Type: ast.NonNullNamedType("String", nil)},
+				}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		object    string
+		relations map[string]string
+		expected  []CreateAuthzCheck
+	}{
+		{
+			name:   "owner checked with the create relation of the organization",
+			object: "Subcontrol",
+			expected: []CreateAuthzCheck{
+				{Name: "ownerID", GoName: "OwnerID", ObjectType: "organization", Relation: "can_create_subcontrol", Nillable: true},
+			},
+		},
+		{
+			name:   "configured parent ids",
+			object: "Subcontrol",
+			relations: map[string]string{
+				"controlID":        "can_edit",
+				"internalPolicyID": "can_view",
+			},
+			expected: []CreateAuthzCheck{
+				{Name: "ownerID", GoName: "OwnerID", ObjectType: "organization", Relation: "can_create_subcontrol", Nillable: true},
+				{Name: "controlID", GoName: "ControlID", ObjectType: "control", Relation: "can_edit"},
+				{Name: "internalPolicyID", GoName: "InternalPolicyID", ObjectType: "internal_policy", Relation: "can_view", Nillable: true},
+			},
+		},
+		{
+			name:      "owner relation override",
+			object:    "Subcontrol",
+			relations: map[string]string{"ownerID": "can_create_control"},
+			expected: []CreateAuthzCheck{
+				{Name: "ownerID", GoName: "OwnerID", ObjectType: "organization", Relation: "can_create_control", Nillable: true},
+			},
+		},
+		{
+			name:      "relation on a field not named after an object",
+			object:    "Subcontrol",
+			relations: map[string]string{"controlStatusID": "can_view", "assignedToUserID": "can_view"},
+			expected: []CreateAuthzCheck{
+				{Name: "ownerID", GoName: "OwnerID", ObjectType: "organization", Relation: "can_create_subcontrol", Nillable: true},
+			},
+		},
+		{
+			name:   "no id fields",
+			object: "Note",
+		},
+		{
+			name:   "no create input",
+			object: "Task",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, createAuthzChecks(tt.object, tt.relations, data))
+		})
+	}
+}
+
+func TestWithBulkAuthzPrecheck(t *testing.T) {
+	p := NewWithOptions()
+	assert.False(t, p.BulkAuthzPrecheck)
+	assert.Empty(t, p.CreateAuthzRelations)
+
+	p = NewWithOptions(
+		WithBulkAuthzPrecheck(),
+		WithCreateAuthzRelations(map[string]string{"controlID": "can_edit"}),
+		WithCreateAuthzRelations(map[string]string{"programID": "can_view"}),
+	)
+	assert.True(t, p.BulkAuthzPrecheck)
+	assert.Equal(t, map[string]string{"controlID": "can_edit", "programID": "can_view"}, p.CreateAuthzRelations)
+}
+
+func TestGenerateSampleCSVUpsert(t *testing.T) {
+	tempDir := t.TempDir()
+
+	object := Object{
+		Name:          "Control",
+		Fields:        []string{"RefCode", "Title"},
+		OperationType: "upsert",
+		CSVFieldMappings: []CSVFieldMapping{
+			{CSVColumn: "OwnerEmail", TargetField: "OwnerID"},
+		},
+	}
+
+	require.NoError(t, generateSampleCSV(object, tempDir))
+
+	content, err := os.ReadFile(tempDir + "/sample_control_upsert.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ID,RefCode,Title,OwnerEmail\n,example_refcode,example_title,example_owneremail\n", string(content))
+}
+
+func TestExtractObjectNameFromCSVExportQuery(t *testing.T) {
+	assert.Equal(t, "Control", extractObjectNameFromCSVExportQuery("exportControlCSV"))
+	assert.Equal(t, "Policy", extractObjectNameFromCSVExportQuery("ExportPolicyCsv"))
+	assert.Empty(t, extractObjectNameFromCSVExportQuery("exportCSV"))
+	assert.Empty(t, extractObjectNameFromCSVExportQuery("controls"))
+	assert.Empty(t, extractObjectNameFromCSVExportQuery("exportControl"))
+}
+
+func TestGenerateCodeDryRunExport(t *testing.T) {
+	report := genreport.New()
+	resolverDir := t.TempDir()
+
+	p := NewWithOptions(WithDryRun(report))
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: resolverDir},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{Name: "Mutation"},
+			Query: &ast.Definition{
+				Name: "Query",
+				Fields: ast.FieldList{
+					{Name: "controls"},
+					{Name: "exportControlCSV"},
+				},
+			},
+			Types: map[string]*ast.Definition{
+				"CreateControlInput": {Name: "CreateControlInput", Fields: ast.FieldList{{Name: "refCode"}}},
+			},
+		},
+	}
+
+	require.NoError(t, p.GenerateCode(data))
+
+	require.Len(t, report.Bulk, 1)
+	assert.Equal(t, genreport.BulkObject{
+		Object:    "Control",
+		Mutation:  "exportControlCSV",
+		Operation: "export",
+		Fields:    []string{"RefCode"},
+	}, report.Bulk[0])
+}
+
+func TestExportColumns(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"String":        {Name: "String", Kind: ast.Scalar},
+				"ID":            {Name: "ID", Kind: ast.Scalar},
+				"ControlStatus": {Name: "ControlStatus", Kind: ast.Enum},
+				"Program":       {Name: "Program", Kind: ast.Object},
+				"Control": {Name: "Control", Kind: ast.Object, Fields: ast.FieldList{
+					{Name: "id", Type: ast.NonNullNamedType("ID", nil)},
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "status", Type: ast.NamedType("ControlStatus", nil)},
+					{Name: "tags", Type: ast.ListType(ast.NonNullNamedType("String", nil), nil)},
+					{Name: "controlOwnerID", Type: ast.NamedType("ID", nil)},
+					{Name: "programs", Type: ast.ListType(ast.NonNullNamedType("Program", nil), nil)},
+				}},
+			},
+		},
+	}
+
+	object := Object{
+		Name:   "Control",
+		Fields: []string{"RefCode", "Status", "Tags", "ProgramIDs"},
+		CSVFieldMappings: []CSVFieldMapping{
+			{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"},
+			{CSVColumn: "ProgramNames", TargetField: "ProgramIDs", IsSlice: true},
+			{CSVColumn: "RefCode", TargetField: "RefCode"},
+		},
+	}
+
+	assert.Equal(t, []ExportColumn{
+		{Header: "ID", GoName: "ID"},
+		{Header: "RefCode", GoName: "RefCode"},
+		{Header: "Status", GoName: "Status"},
+		{Header: "Tags", GoName: "Tags"},
+		{Header: "ProgramIDs"},
+		{Header: "ControlOwnerEmail", GoName: "ControlOwnerID"},
+		{Header: "ProgramNames"},
+	}, exportColumns(object, data))
+}
+
+func TestExportColumnsUpdateRoundTrip(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"String": {Name: "String", Kind: ast.Scalar},
+				"ID":     {Name: "ID", Kind: ast.Scalar},
+				"Control": {Name: "Control", Kind: ast.Object, Fields: ast.FieldList{
+					{Name: "id", Type: ast.NonNullNamedType("ID", nil)},
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "address", Type: ast.NamedType("String", nil)},
+					{Name: "ownerID", Type: ast.NamedType("ID", nil)},
+				}},
+				"CreateControlInput": {Name: "CreateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "address", Type: ast.NamedType("String", nil)},
+					{Name: "ownerID", Type: ast.NamedType("ID", nil)},
+					{Name: "programIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+				}},
+				"UpdateControlInput": {Name: "UpdateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NamedType("String", nil)},
+					{Name: "address", Type: ast.NamedType("String", nil)},
+					{Name: "clearAddress", Type: ast.NamedType("Boolean", nil)},
+					{Name: "appendTags", Type: ast.ListType(ast.NonNullNamedType("String", nil), nil)},
+					{Name: "addProgramIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+					{Name: "removeProgramIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+				}},
+			},
+		},
+	}
+
+	mappings := []CSVFieldMapping{{CSVColumn: "ProgramNames", TargetField: "ProgramIDs", IsSlice: true}}
+
+	update := Object{
+		Name:                 "Control",
+		Fields:               getCreateInputFields("Control", data),
+		SampleFields:         getSampleFields("UpdateControlInput", data),
+		OperationType:        "update",
+		HasCSVUpdateMutation: true,
+		CSVFieldMappings:     mappings,
+	}
+
+	known, required := csvHeaderCheck(update)
+
+	export := Object{Name: "Control", Fields: getExportFields("Control", data), OperationType: "export", CSVFieldMappings: mappings}
+	columns := exportColumns(export, data)
+
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.Header)
+	}
+
+	assert.Equal(t, []string{"ID", "RefCode", "Address", "ProgramNames"}, headers)
+
+	// every exported column is accepted by the CSV bulk update and the export has every required column
+	for _, header := range headers {
+		assert.Contains(t, known, header)
+	}
+
+	for _, r := range required {
+		assert.Contains(t, headers, r.Header)
+	}
+}
+
+func TestIsUpdateOperationField(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{name: "clearTags", expected: true},
+		{name: "appendTags", expected: true},
+		{name: "addProgramIDs", expected: true},
+		{name: "removeProgramIDs", expected: true},
+		{name: "address"},
+		{name: "clear"},
+		{name: "refCode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isUpdateOperationField(tt.name))
+		})
+	}
+}
+
+func TestSampleValue(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"ControlStatus":   {Name: "ControlStatus", Kind: ast.Enum, EnumValues: ast.EnumValueList{{Name: "APPROVED"}, {Name: "DRAFT"}}},
+				"CreateNoteInput": {Name: "CreateNoteInput", Kind: ast.InputObject},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		field    *ast.FieldDefinition
+		expected string
+	}{
+		{name: "string", field: &ast.FieldDefinition{Name: "refCode", Type: ast.NonNullNamedType("String", nil)}, expected: "example_refcode"},
+		{name: "enum", field: &ast.FieldDefinition{Name: "status", Type: ast.NamedType("ControlStatus", nil)}, expected: "APPROVED"},
+		{name: "boolean", field: &ast.FieldDefinition{Name: "system", Type: ast.NamedType("Boolean", nil)}, expected: "true"},
+		{name: "int", field: &ast.FieldDefinition{Name: "priority", Type: ast.NamedType("Int", nil)}, expected: "1"},
+		{name: "float", field: &ast.FieldDefinition{Name: "score", Type: ast.NamedType("Float", nil)}, expected: "1.5"},
+		{name: "time", field: &ast.FieldDefinition{Name: "dueDate", Type: ast.NamedType("Time", nil)}, expected: sampleTimestamp},
+		{name: "map", field: &ast.FieldDefinition{Name: "details", Type: ast.NamedType("Map", nil)}, expected: "{}"},
+		{name: "string list", field: &ast.FieldDefinition{Name: "tags", Type: ast.ListType(ast.NonNullNamedType("String", nil), nil)}, expected: `["example_tags"]`},
+		{name: "enum list", field: &ast.FieldDefinition{Name: "statuses", Type: ast.ListType(ast.NonNullNamedType("ControlStatus", nil), nil)}, expected: `["APPROVED"]`},
+		{name: "optional id", field: &ast.FieldDefinition{Name: "ownerID", Type: ast.NamedType("ID", nil)}},
+		{name: "optional id list", field: &ast.FieldDefinition{Name: "programIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)}},
+		{name: "required id", field: &ast.FieldDefinition{Name: "ownerID", Type: ast.NonNullNamedType("ID", nil)}, expected: ""},
+		{name: "input object", field: &ast.FieldDefinition{Name: "note", Type: ast.NamedType("CreateNoteInput", nil)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sampleValue(tt.field, data))
+		})
+	}
+}
+
+func TestGenerateSampleCSVTypedValues(t *testing.T) {
+	tempDir := t.TempDir()
+
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"ControlStatus": {Name: "ControlStatus", Kind: ast.Enum, EnumValues: ast.EnumValueList{{Name: "APPROVED"}}},
+				"CreateControlInput": {Name: "CreateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "status", Type: ast.NamedType("ControlStatus", nil)},
+					{Name: "tags", Type: ast.ListType(ast.NonNullNamedType("String", nil), nil)},
+					{Name: "ownerID", Type: ast.NamedType("ID", nil)},
+				}},
+			},
+		},
+	}
+
+	object := Object{
+		Name:          "Control",
+		Fields:        getCreateInputFields("Control", data),
+		SampleFields:  getSampleFields("CreateControlInput", data),
+		OperationType: "create",
+	}
+
+	assert.Equal(t, []string{"RefCode"}, requiredFields(object))
+
+	require.NoError(t, generateSampleCSV(object, tempDir))
+
+	content, err := os.ReadFile(tempDir + "/sample_control.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, "RefCode*,Status,Tags,OwnerId\nexample_refcode,APPROVED,\"[\"\"example_tags\"\"]\",\n", string(content))
+}
+
+func TestGenerateSampleCSVUpdate(t *testing.T) {
+	tempDir := t.TempDir()
+
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"UpdateControlInput": {Name: "UpdateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "title", Type: ast.NamedType("String", nil)},
+					{Name: "clearTitle", Type: ast.NamedType("Boolean", nil)},
+				}},
+			},
+		},
+	}
+
+	object := Object{
+		Name:                 "Control",
+		OperationType:        "update",
+		HasCSVUpdateMutation: true,
+		SampleFields:         getSampleFields("UpdateControlInput", data),
+		CSVFieldMappings: []CSVFieldMapping{
+			{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"},
+		},
+	}
+
+	require.True(t, hasSampleCSV(object))
+	assert.Equal(t, []string{"ID"}, requiredFields(object))
+
+	require.NoError(t, generateSampleCSV(object, tempDir))
+
+	content, err := os.ReadFile(tempDir + "/sample_control_update.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, "ID*,Title,ClearTitle,ControlOwnerEmail\n,example_title,,example_controlowneremail\n", string(content))
+
+	// without a CSV bulk update mutation there is nothing to upload the sample to
+	object.HasCSVUpdateMutation = false
+	assert.False(t, hasSampleCSV(object))
+}
+
+func TestSampleManifestFile(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"ControlStatus": {Name: "ControlStatus", Kind: ast.Enum, EnumValues: ast.EnumValueList{{Name: "APPROVED"}, {Name: "DRAFT"}}},
+				"CreateControlInput": {Name: "CreateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "refCode", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "status", Type: ast.NamedType("ControlStatus", nil)},
+				}},
+			},
+		},
+	}
+
+	object := Object{
+		Name:          "Control",
+		OperationType: "upsert",
+		SampleFields:  getSampleFields("CreateControlInput", data),
+		CSVFieldMappings: []CSVFieldMapping{
+			{CSVColumn: "ProgramNames", TargetField: "ProgramIDs", IsSlice: true},
+		},
+	}
+
+	assert.Equal(t, CSVManifestFile{
+		Object:    "Control",
+		Operation: "upsert",
+		File:      "sample_control_upsert.csv",
+		Columns: []CSVManifestColumn{
+			{Header: "ID", Type: "ID"},
+			{Header: "RefCode", Type: "String!", Required: true, Example: "example_refcode"},
+			{Header: "Status", Type: "ControlStatus", EnumValues: []string{"APPROVED", "DRAFT"}, Example: "APPROVED"},
+			{Header: "ProgramNames", Type: "[String!]", MappedTo: "ProgramIDs", Example: `["example_programnames1","example_programnames2"]`},
+		},
+	}, sampleManifestFile(object))
+}
+
+func TestWriteCSVManifest(t *testing.T) {
+	tempDir := t.TempDir()
+
+	manifest := CSVManifest{Files: []CSVManifestFile{
+		sampleManifestFile(Object{Name: "Task", OperationType: "create", Fields: []string{"Title"}}),
+	}}
+
+	require.NoError(t, writeCSVManifest(manifest, tempDir))
+
+	content, err := os.ReadFile(tempDir + "/csv_manifest.json")
+	require.NoError(t, err)
+
+	var decoded CSVManifest
+	require.NoError(t, json.Unmarshal(content, &decoded))
+	assert.Equal(t, manifest, decoded)
+	assert.Equal(t, "sample_task.csv", decoded.Files[0].File)
+
+	// the manifest is readable like the sample CSVs, without their write permission for the group and others
+	require.NoError(t, generateSampleCSV(Object{Name: "Task", OperationType: "create", Fields: []string{"Title"}}, tempDir))
+
+	manifestInfo, err := os.Stat(tempDir + "/csv_manifest.json")
+	require.NoError(t, err)
+
+	sampleInfo, err := os.Stat(tempDir + "/sample_task.csv")
+	require.NoError(t, err)
+
+	assert.Equal(t, sampleInfo.Mode().Perm()&csvManifestPerm, manifestInfo.Mode().Perm())
+}
+
+func TestCSVHeaderCheck(t *testing.T) {
+	tests := []struct {
+		name             string
+		object           Object
+		expectedUpload   bool
+		expectedHeaders  []string
+		expectedRequired []RequiredCSVHeader
+		expectedFunc     string
+	}{
+		{
+			name: "create",
+			object: Object{
+				Name:          "Control",
+				OperationType: "create",
+				SampleFields: []SampleField{
+					{Name: "RefCode", Required: true},
+					{Name: "OwnerId", Required: true},
+					{Name: "Title"},
+				},
+				CSVFieldMappings: []CSVFieldMapping{{CSVColumn: "OwnerEmail", TargetField: "OwnerID"}},
+			},
+			expectedUpload:  true,
+			expectedHeaders: []string{"RefCode", "OwnerId", "Title", "OwnerEmail"},
+			expectedRequired: []RequiredCSVHeader{
+				{Header: "RefCode"},
+				{Header: "OwnerId", Alternatives: []string{"OwnerEmail"}},
+			},
+			expectedFunc: "validateControlCSVHeader",
+		},
+		{
+			name: "update",
+			object: Object{
+				Name:                 "Control",
+				OperationType:        "update",
+				HasCSVUpdateMutation: true,
+				SampleFields:         []SampleField{{Name: "Title"}},
+			},
+			expectedUpload:   true,
+			expectedHeaders:  []string{"ID", "Title"},
+			expectedRequired: []RequiredCSVHeader{{Header: "ID"}},
+			expectedFunc:     "validateControlCSVUpdateHeader",
+		},
+		{
+			name: "upsert",
+			object: Object{
+				Name:                 "Control",
+				OperationType:        "upsert",
+				HasCSVUpsertMutation: true,
+				SampleFields:         []SampleField{{Name: "RefCode", Required: true}},
+			},
+			expectedUpload:   true,
+			expectedHeaders:  []string{"ID", "RefCode"},
+			expectedRequired: []RequiredCSVHeader{{Header: "RefCode"}},
+			expectedFunc:     "validateControlCSVUpsertHeader",
+		},
+		{
+			name:         "update without csv upload",
+			object:       Object{Name: "Control", OperationType: "update"},
+			expectedFunc: "validateControlCSVUpdateHeader",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedUpload, hasCSVUpload(tt.object))
+			assert.Equal(t, tt.expectedFunc, tt.object.CSVHeaderValidator())
+
+			if !tt.expectedUpload {
+				return
+			}
+
+			headers, required := csvHeaderCheck(tt.object)
+			assert.Equal(t, tt.expectedHeaders, headers)
+			assert.Equal(t, tt.expectedRequired, required)
+		})
+	}
+}
+
+func TestValidateCSVFieldMappings(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"ActionPlan": {Name: "ActionPlan", Kind: ast.Object},
+				"CreateActionPlanInput": {Name: "CreateActionPlanInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "name", Type: ast.NonNullNamedType("String", nil)},
+					{Name: "assignedToUserID", Type: ast.NamedType("ID", nil)},
+					{Name: "blockedGroupIDs", Type: ast.ListType(ast.NonNullNamedType("ID", nil), nil)},
+				}},
+				"Program": {Name: "Program", Kind: ast.Object},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		mappings CSVFieldMappingsJSON
+		errs     []string
+	}{
+		{
+			name: "valid",
+			mappings: CSVFieldMappingsJSON{
+				"ActionPlan": {
+					{CSVColumn: "AssignedToUserEmail", TargetField: "AssignedToUserID"},
+					{CSVColumn: "BlockedGroupNames", TargetField: "BlockedGroupIds", IsSlice: true},
+				},
+			},
+		},
+		{
+			name: "unknown schema",
+			mappings: CSVFieldMappingsJSON{
+				"Acton": {{CSVColumn: "OwnerEmail", TargetField: "OwnerID"}},
+			},
+			errs: []string{"schema Acton is not in the graphql schema"},
+		},
+		{
+			name: "schema without create input",
+			mappings: CSVFieldMappingsJSON{
+				"Program": {{CSVColumn: "OwnerEmail", TargetField: "OwnerID"}},
+			},
+			errs: []string{"schema Program has no CreateProgramInput"},
+		},
+		{
+			name: "unknown target field",
+			mappings: CSVFieldMappingsJSON{
+				"ActionPlan": {{CSVColumn: "OwnerEmail", TargetField: "OwnerID"}},
+			},
+			errs: []string{"ActionPlan.OwnerEmail: target field OwnerID is not a field of CreateActionPlanInput"},
+		},
+		{
+			name: "slice mismatch",
+			mappings: CSVFieldMappingsJSON{
+				"ActionPlan": {
+					{CSVColumn: "AssignedToUserEmail", TargetField: "AssignedToUserID", IsSlice: true},
+					{CSVColumn: "BlockedGroupNames", TargetField: "BlockedGroupIDs"},
+				},
+			},
+			errs: []string{
+				"ActionPlan.AssignedToUserEmail: isSlice is true but target field AssignedToUserID has type ID",
+				"ActionPlan.BlockedGroupNames: isSlice is false but target field BlockedGroupIDs has type [ID!]",
+			},
+		},
+		{
+			name: "duplicate and colliding columns",
+			mappings: CSVFieldMappingsJSON{
+				"ActionPlan": {
+					{CSVColumn: "Name", TargetField: "AssignedToUserID"},
+					{CSVColumn: "AssignedToUserEmail", TargetField: "AssignedToUserID"},
+					{CSVColumn: "AssignedToUserEmail", TargetField: "AssignedToUserID"},
+				},
+			},
+			errs: []string{
+				"ActionPlan.Name: column collides with a field of CreateActionPlanInput",
+				"ActionPlan.AssignedToUserEmail: column is mapped more than once",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCSVFieldMappings(tt.mappings, data)
+			if len(tt.errs) == 0 {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidCSVFieldMappings)
+
+			for _, msg := range tt.errs {
+				assert.ErrorContains(t, err, msg)
+			}
+
+			assert.Len(t, strings.Split(err.Error(), "\n"), len(tt.errs))
+		})
+	}
+}
+
+func TestStrictCSVFieldMappings(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"Control": {Name: "Control", Kind: ast.Object},
+				"CreateControlInput": {Name: "CreateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "controlOwnerID", Type: ast.NamedType("ID", nil)},
+				}},
+			},
+		},
+	}
+
+	tempDir := t.TempDir()
+
+	valid := tempDir + "/valid.json"
+	require.NoError(t, os.WriteFile(valid, []byte(`{"Control": [{"csvColumn": "ControlOwnerEmail", "targetField": "ControlOwnerID"}]}`), 0o600))
+
+	invalid := tempDir + "/invalid.json"
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"Control": [{"csvColumn": "ControlOwnerEmail", "targetField": "OwnerID"}]}`), 0o600))
+
+	malformed := tempDir + "/malformed.json"
+	require.NoError(t, os.WriteFile(malformed, []byte("not valid json"), 0o600))
+
+	tests := []struct {
+		name        string
+		path        string
+		strict      bool
+		expectedErr bool
+		expectedLen int
+	}{
+		{name: "strict valid", path: valid, strict: true, expectedLen: 1},
+		{name: "strict no file configured", strict: true},
+		{name: "strict missing file", path: tempDir + "/missing.json", strict: true, expectedErr: true},
+		{name: "strict malformed file", path: malformed, strict: true, expectedErr: true},
+		{name: "strict invalid mapping", path: invalid, strict: true, expectedErr: true},
+		{name: "missing file", path: tempDir + "/missing.json"},
+		{name: "malformed file", path: malformed},
+		{name: "invalid mapping is kept", path: invalid, expectedLen: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []Options{WithCSVFieldMappingsFile(tt.path)}
+			if tt.strict {
+				opts = append(opts, WithStrictCSVFieldMappings())
+			}
+
+			mappings, err := NewWithOptions(opts...).csvFieldMappings(data)
+			if tt.expectedErr {
+				require.ErrorIs(t, err, ErrInvalidCSVFieldMappings)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, mappings, tt.expectedLen)
+		})
+	}
+}
+
+func TestReadCSVFieldMappings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	controlYAML := `Control:
+  - csvColumn: ControlOwnerEmail
+    targetField: ControlOwnerID
+  - csvColumn: PlatformNames
+    targetField: PlatformIds
+    isSlice: true
+`
+
+	mappingsDir := filepath.Join(tempDir, "mappings")
+	require.NoError(t, os.Mkdir(mappingsDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(mappingsDir, "control.yaml"), []byte(controlYAML), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(mappingsDir, "risk.json"), []byte(`{"Risk": [{"csvColumn": "StakeholderName", "targetField": "StakeholderID"}]}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(mappingsDir, "zz_control.yml"), []byte("Control:\n  - csvColumn: DelegateName\n    targetField: DelegateID\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(mappingsDir, "README.md"), []byte("# mappings"), 0o600))
+
+	tests := []struct {
+		name        string
+		path        string
+		expected    CSVFieldMappingsJSON
+		expectedErr error
+	}{
+		{
+			name: "yaml file",
+			path: filepath.Join(mappingsDir, "control.yaml"),
+			expected: CSVFieldMappingsJSON{
+				"Control": {
+					{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"},
+					{CSVColumn: "PlatformNames", TargetField: "PlatformIds", IsSlice: true},
+				},
+			},
+		},
+		{
+			name: "directory merged per schema",
+			path: mappingsDir,
+			expected: CSVFieldMappingsJSON{
+				"Control": {
+					{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"},
+					{CSVColumn: "PlatformNames", TargetField: "PlatformIds", IsSlice: true},
+					{CSVColumn: "DelegateName", TargetField: "DelegateID"},
+				},
+				"Risk": {
+					{CSVColumn: "StakeholderName", TargetField: "StakeholderID"},
+				},
+			},
+		},
+		{
+			name: "glob",
+			path: filepath.Join(mappingsDir, "*.json"),
+			expected: CSVFieldMappingsJSON{
+				"Risk": {
+					{CSVColumn: "StakeholderName", TargetField: "StakeholderID"},
+				},
+			},
+		},
+		{
+			name:        "glob without matches",
+			path:        filepath.Join(mappingsDir, "*.toml"),
+			expectedErr: fs.ErrNotExist,
+		},
+		{
+			name:        "missing file",
+			path:        filepath.Join(tempDir, "missing.yaml"),
+			expectedErr: fs.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings, err := readCSVFieldMappings(tt.path)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mappings)
+		})
+	}
+}
+
+func TestWithCSVFieldMappings(t *testing.T) {
+	data := codegen.Data{
+		Schema: &ast.Schema{
+			Types: map[string]*ast.Definition{
+				"Control": {Name: "Control", Kind: ast.Object},
+				"CreateControlInput": {Name: "CreateControlInput", Kind: ast.InputObject, Fields: ast.FieldList{
+					{Name: "controlOwnerID", Type: ast.NamedType("ID", nil)},
+					{Name: "delegateID", Type: ast.NamedType("ID", nil)},
+				}},
+			},
+		},
+	}
+
+	filePath := filepath.Join(t.TempDir(), "csv_field_mappings.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"Control": [{"csvColumn": "ControlOwnerEmail", "targetField": "ControlOwnerID"}]}`), 0o600))
+
+	goMappings := map[string][]CSVFieldMapping{
+		"Control": {{CSVColumn: "DelegateName", TargetField: "DelegateID"}},
+	}
+
+	plugin := NewWithOptions(
+		WithCSVFieldMappingsFile(filePath),
+		WithCSVFieldMappings(goMappings),
+		WithStrictCSVFieldMappings(),
+	)
+
+	mappings, err := plugin.csvFieldMappings(data)
+	require.NoError(t, err)
+	assert.Equal(t, CSVFieldMappingsJSON{
+		"Control": {
+			{CSVColumn: "ControlOwnerEmail", TargetField: "ControlOwnerID"},
+			{CSVColumn: "DelegateName", TargetField: "DelegateID"},
+		},
+	}, mappings)
+
+	// the go mappings are not modified by the merge
+	assert.Len(t, goMappings["Control"], 1)
+
+	// go mappings are validated the same as the mappings files
+	plugin = NewWithOptions(
+		WithCSVFieldMappings(map[string][]CSVFieldMapping{"Control": {{CSVColumn: "DelegateName", TargetField: "OwnerID"}}}),
+		WithStrictCSVFieldMappings(),
+	)
+
+	_, err = plugin.csvFieldMappings(data)
+	require.ErrorIs(t, err, ErrInvalidCSVFieldMappings)
+}
+
+func TestGenerateCodeDryRunArgument(t *testing.T) {
+	report := genreport.New()
+
+	dryRun := ast.ArgumentDefinitionList{{Name: "dryRun", Type: ast.NamedType("Boolean", nil)}}
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: t.TempDir()},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{
+				Name: "Mutation",
+				Fields: ast.FieldList{
+					{Name: "createBulkControl", Arguments: dryRun},
+					{Name: "updateBulkControl"},
+					{Name: "updateBulkCSVControl", Arguments: dryRun},
+					{Name: "deleteBulkControl"},
+					{Name: "upsertBulkControl"},
+					{Name: "createOrUpdateBulkCSVControl", Arguments: dryRun},
+					{Name: "createBulkCSVUpdateLog"},
+					{Name: "createBulkUpdateLog"},
+				},
+			},
+			Types: map[string]*ast.Definition{},
+		},
+	}
+
+	require.NoError(t, NewWithOptions(WithDryRun(report)).GenerateCode(data))
+
+	dryRuns := map[string]bool{}
+	for _, object := range report.Bulk {
+		dryRuns[object.Object+"/"+object.Operation] = object.DryRun
+	}
+
+	assert.Equal(t, map[string]bool{
+		"Control/create":   true,
+		"Control/update":   true,
+		"Control/delete":   false,
+		"Control/upsert":   true,
+		"UpdateLog/create": false,
+	}, dryRuns)
+}
+
+func TestGenerateCodeBulkJob(t *testing.T) {
+	report := genreport.New()
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: t.TempDir()},
+		},
+		Schema: &ast.Schema{
+			Mutation: &ast.Definition{
+				Name: "Mutation",
+				Fields: ast.FieldList{
+					{Name: "createBulkControl", Type: ast.NonNullNamedType("ControlBulkCreatePayload", nil)},
+					{Name: "updateBulkControl", Type: ast.NonNullNamedType("ControlBulkUpdatePayload", nil)},
+					{Name: "createBulkCSVControlJob", Type: ast.NonNullNamedType("BulkJob", nil)},
+					{Name: "updateBulkCSVControlJob", Type: ast.NonNullNamedType("BulkJob", nil)},
+					{Name: "createBulkScheduledJob", Type: ast.NonNullNamedType("ScheduledJobBulkCreatePayload", nil)},
+					{Name: "createBulkCSVScheduledJob", Type: ast.NonNullNamedType("ScheduledJobBulkCreatePayload", nil)},
+				},
+			},
+			Types: map[string]*ast.Definition{},
+		},
+	}
+
+	require.NoError(t, NewWithOptions(WithDryRun(report)).GenerateCode(data))
+
+	jobs := map[string]bool{}
+	csvUpdates := map[string]bool{}
+
+	for _, object := range report.Bulk {
+		jobs[object.Object+"/"+object.Operation] = object.BulkJob
+		csvUpdates[object.Object+"/"+object.Operation] = object.HasCSVUpdateMutation
+	}
+
+	assert.Equal(t, map[string]bool{
+		"Control/create":      true,
+		"Control/update":      true,
+		"ScheduledJob/create": false,
+	}, jobs)
+
+	// the job variants do not add a CSV mutation for an object named ControlJob
+	assert.Equal(t, map[string]bool{
+		"Control/create":      false,
+		"Control/update":      false,
+		"ScheduledJob/create": true,
+	}, csvUpdates)
+}
+
+func TestObjectsByEntity(t *testing.T) {
+	objects := []Object{
+		{Name: "Control", OperationType: "create"},
+		{Name: "InternalPolicy", OperationType: "create"},
+		{Name: "Control", OperationType: "update"},
+		{Name: "InternalPolicy", OperationType: "delete"},
+		{Name: "Control", OperationType: "export"},
+	}
+
+	groups := objectsByEntity(objects)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, []Object{objects[0], objects[2], objects[4]}, groups[0])
+	assert.Equal(t, []Object{objects[1], objects[3]}, groups[1])
+
+	assert.Equal(t, "bulk_control.go", bulkEntityFile("Control"))
+	assert.Equal(t, "bulk_internal_policy.go", bulkEntityFile("InternalPolicy"))
+	assert.Equal(t, "bulk_trust_center_faq.go", bulkEntityFile("TrustCenterFAQ"))
+}
+
+func TestRemoveStaleBulkFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	generated := "package graphapi\n\n" + bulkFileNotice + "\n"
+	files := map[string]string{
+		"bulk.go":                generated,
+		"bulk_control.go":        generated,
+		"bulk_removed_schema.go": generated,
+		"bulk_helpers.go":        "package graphapi\n",
+		"bulk_control_test.go":   generated,
+		"control.resolvers.go":   generated,
+	}
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	require.NoError(t, removeStaleBulkFiles(dir, map[string]bool{"bulk.go": true, "bulk_control.go": true}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	remaining := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+
+	// only the generated entity file that was not written is removed
+	assert.ElementsMatch(t, []string{"bulk.go", "bulk_control.go", "bulk_helpers.go", "bulk_control_test.go", "control.resolvers.go"}, remaining)
+
+	require.NoError(t, removeStaleBulkFiles(filepath.Join(dir, "missing"), nil))
+}
@@ -0,0 +1,14 @@
+package bulkgen
+
+import "errors"
+
+// ErrInvalidCSVFieldMappings is returned with strict CSV field mappings when the mappings file can not be read
+// or a mapping does not match the graphql schema
+var ErrInvalidCSVFieldMappings = errors.New("invalid csv field mappings")
+
+// ErrInvalidMutationNamePattern is returned when a mutation name pattern does not have exactly one entity
+// placeholder and one operation, or has an unknown placeholder
+var ErrInvalidMutationNamePattern = errors.New("invalid mutation name pattern")
+
+// ErrAmbiguousMutationName is returned when a mutation name matches more than one mutation name pattern
+var ErrAmbiguousMutationName = errors.New("ambiguous bulk mutation name")
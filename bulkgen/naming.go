@@ -0,0 +1,296 @@
+package bulkgen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DefaultMutationNamePatterns are the names of the bulk and CSV bulk mutations used when
+// WithMutationNamePatterns is not set, e.g. createBulkControl, bulkUpdateControl and updateBulkCSVControl
+var DefaultMutationNamePatterns = []string{
+	"{op}Bulk{Entity}",
+	"bulk{Op}{Entity}",
+	"{op}BulkCSV{Entity}",
+	"bulkCSV{Op}{Entity}",
+	"csvBulk{Op}{Entity}",
+}
+
+// mutationNameOperations are the operations matched by the {op} placeholder, in the order they are tried
+// so createOrUpdate is matched before create
+var mutationNameOperations = []string{"createOrUpdate", "create", "update", "delete", "upsert"}
+
+// mutationNamePlaceholder matches the placeholders of a mutation name pattern, e.g. {Entity}
+var mutationNamePlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// mutationNamePattern is a parsed mutation name pattern
+type mutationNamePattern struct {
+	// pattern is the pattern as configured, e.g. {op}Bulk{Entity}
+	pattern string
+	// re matches the mutation names of the pattern, with the entity and op groups
+	re *regexp.Regexp
+	// operation is the operation of a pattern without an {op} placeholder, e.g. create for {entity}BatchCreate
+	operation string
+	// csv is true when the pattern is for the CSV bulk mutations, the pattern contains CSV
+	csv bool
+	// lowerEntity is true when the entity is lower camel case in the name, e.g. {entity}BatchCreate
+	lowerEntity bool
+}
+
+// mutationName is a bulk mutation matched by a mutation name pattern
+type mutationName struct {
+	// object is the name of the object of the mutation, e.g. Control
+	object string
+	// operation is the bulk operation of the mutation, create, update, delete or upsert
+	operation string
+	// csv is true for the CSV bulk mutations
+	csv bool
+	// pattern is the pattern that matched the mutation
+	pattern string
+}
+
+// MutationNameMatcher matches the names of the bulk and CSV bulk mutations with the mutation name patterns,
+// so resolvergen classifies the same mutations as bulk mutations as bulkgen generates the resolvers for
+type MutationNameMatcher struct {
+	patterns []mutationNamePattern
+}
+
+// MutationName is a bulk or CSV bulk mutation matched by a MutationNameMatcher
+type MutationName struct {
+	// Object is the name of the object of the mutation, e.g. Control
+	Object string
+	// Operation is the bulk operation of the mutation, create, update, delete or upsert
+	Operation string
+	// CSV is true for the CSV bulk mutations
+	CSV bool
+}
+
+// NewMutationNameMatcher parses the mutation name patterns, the DefaultMutationNamePatterns are used
+// when no patterns are set. Every invalid pattern is reported with ErrInvalidMutationNamePattern
+func NewMutationNameMatcher(patterns ...string) (*MutationNameMatcher, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultMutationNamePatterns
+	}
+
+	parsed, err := parseMutationNamePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MutationNameMatcher{patterns: parsed}, nil
+}
+
+// Match returns the bulk mutation matched by the name, false when the name does not match any pattern.
+// A name matching more than one pattern of the same kind returns ErrAmbiguousMutationName
+func (m *MutationNameMatcher) Match(name string) (MutationName, bool, error) {
+	matched, ok, err := matchMutationName(m.patterns, name)
+	if err != nil || !ok {
+		return MutationName{}, false, err
+	}
+
+	return MutationName{Object: matched.object, Operation: matched.operation, CSV: matched.csv}, true, nil
+}
+
+// parseMutationNamePatterns parses the mutation name patterns, duplicate patterns are skipped and
+// every invalid pattern is reported
+func parseMutationNamePatterns(patterns []string) ([]mutationNamePattern, error) {
+	parsed := make([]mutationNamePattern, 0, len(patterns))
+	seen := make(map[string]bool, len(patterns))
+
+	var errs []error
+
+	for _, pattern := range patterns {
+		if seen[pattern] {
+			continue
+		}
+
+		seen[pattern] = true
+
+		p, err := parseMutationNamePattern(pattern)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		parsed = append(parsed, p)
+	}
+
+	return parsed, errors.Join(errs...)
+}
+
+// parseMutationNamePattern parses a pattern with an {entity} or {Entity} placeholder for the object name and an
+// optional {op} or {Op} placeholder for the operation, the text around the placeholders is matched case-insensitively.
+// A pattern without an {op} placeholder must name exactly one operation, e.g. {entity}BatchCreate
+func parseMutationNamePattern(pattern string) (mutationNamePattern, error) {
+	p := mutationNamePattern{pattern: pattern}
+
+	var (
+		expr     strings.Builder
+		literal  strings.Builder
+		entities int
+		ops      int
+		last     int
+	)
+
+	expr.WriteString("(?i)^")
+
+	for _, loc := range mutationNamePlaceholder.FindAllStringSubmatchIndex(pattern, -1) {
+		expr.WriteString(regexp.QuoteMeta(pattern[last:loc[0]]))
+		literal.WriteString(pattern[last:loc[0]])
+
+		last = loc[1]
+
+		switch placeholder := pattern[loc[2]:loc[3]]; placeholder {
+		case "entity", "Entity":
+			entities++
+			p.lowerEntity = placeholder == "entity"
+
+			expr.WriteString("(?P<entity>.+)")
+		case "op", "Op":
+			ops++
+
+			expr.WriteString("(?P<op>" + strings.Join(mutationNameOperations, "|") + ")")
+		default:
+			return p, fmt.Errorf("%w: %q has unknown placeholder {%s}, use {entity} or {op}", ErrInvalidMutationNamePattern, pattern, placeholder)
+		}
+	}
+
+	expr.WriteString(regexp.QuoteMeta(pattern[last:]))
+	expr.WriteString("$")
+	literal.WriteString(pattern[last:])
+
+	if entities != 1 {
+		return p, fmt.Errorf("%w: %q must have one {entity} placeholder", ErrInvalidMutationNamePattern, pattern)
+	}
+
+	if ops > 1 {
+		return p, fmt.Errorf("%w: %q must have at most one {op} placeholder", ErrInvalidMutationNamePattern, pattern)
+	}
+
+	if ops == 0 {
+		operations := literalOperations(literal.String())
+		if len(operations) != 1 {
+			return p, fmt.Errorf("%w: %q must have an {op} placeholder or name one of create, update, delete or upsert", ErrInvalidMutationNamePattern, pattern)
+		}
+
+		p.operation = operations[0]
+	}
+
+	p.re = regexp.MustCompile(expr.String())
+	p.csv = strings.Contains(strings.ToLower(literal.String()), "csv")
+
+	return p, nil
+}
+
+// literalOperations returns the operations named in the text of a pattern, createOrUpdate is an upsert
+func literalOperations(literal string) []string {
+	lower := strings.ReplaceAll(strings.ToLower(literal), "createorupdate", "upsert")
+
+	var operations []string
+
+	for _, op := range []string{"create", "update", "delete", "upsert"} {
+		if strings.Contains(lower, op) {
+			operations = append(operations, op)
+		}
+	}
+
+	return operations
+}
+
+// match returns the object and operation of the mutation when the name matches the pattern
+func (p mutationNamePattern) match(name string) (mutationName, bool) {
+	groups := p.re.FindStringSubmatch(name)
+	if groups == nil {
+		return mutationName{}, false
+	}
+
+	matched := mutationName{
+		object:    groups[p.re.SubexpIndex("entity")],
+		operation: p.operation,
+		csv:       p.csv,
+		pattern:   p.pattern,
+	}
+
+	if i := p.re.SubexpIndex("op"); i >= 0 {
+		matched.operation = strings.ToLower(groups[i])
+		if matched.operation == "createorupdate" {
+			matched.operation = "upsert"
+		}
+	}
+
+	if p.lowerEntity {
+		r, size := utf8.DecodeRuneInString(matched.object)
+		matched.object = string(unicode.ToUpper(r)) + matched.object[size:]
+	}
+
+	return matched, true
+}
+
+// matchMutationName returns the bulk mutation matched by the patterns. A CSV pattern takes precedence over
+// the other patterns, e.g. createBulkCSVControl also matches {op}Bulk{Entity}, but a name matching more than
+// one pattern of the same kind is ambiguous and returns an error
+func matchMutationName(patterns []mutationNamePattern, name string) (mutationName, bool, error) {
+	var csvMatches, bulkMatches []mutationName
+
+	for _, p := range patterns {
+		matched, ok := p.match(name)
+		if !ok {
+			continue
+		}
+
+		if matched.csv {
+			csvMatches = append(csvMatches, matched)
+		} else {
+			bulkMatches = append(bulkMatches, matched)
+		}
+	}
+
+	matches := bulkMatches
+	if len(csvMatches) > 0 {
+		matches = csvMatches
+	}
+
+	switch len(matches) {
+	case 0:
+		return mutationName{}, false, nil
+	case 1:
+		return matches[0], true, nil
+	default:
+		matchedPatterns := make([]string, 0, len(matches))
+		for _, matched := range matches {
+			matchedPatterns = append(matchedPatterns, fmt.Sprintf("%q", matched.pattern))
+		}
+
+		return mutationName{}, false, fmt.Errorf("%w: %s matches the patterns %s, remove or narrow one of them",
+			ErrAmbiguousMutationName, name, strings.Join(matchedPatterns, ", "))
+	}
+}
+
+// matchMutationNames returns the bulk mutations of the schema keyed by mutation name, every mutation
+// matching more than one pattern is reported
+func matchMutationNames(patterns []mutationNamePattern, fields ast.FieldList) (map[string]mutationName, error) {
+	names := make(map[string]mutationName)
+
+	var errs []error
+
+	for _, f := range fields {
+		matched, ok, err := matchMutationName(patterns, f.Name)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if ok {
+			names[f.Name] = matched
+		}
+	}
+
+	return names, errors.Join(errs...)
+}
@@ -0,0 +1,229 @@
+package bulkgen
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+)
+
+func TestMatchDefaultMutationNames(t *testing.T) {
+	patterns, err := parseMutationNamePatterns(DefaultMutationNamePatterns)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		mutationName string
+		expected     *mutationName
+	}{
+		{name: "createBulk prefix", mutationName: "createBulkControl", expected: &mutationName{object: "Control", operation: "create"}},
+		{name: "bulkCreate prefix", mutationName: "bulkCreateControl", expected: &mutationName{object: "Control", operation: "create"}},
+		{name: "updateBulk prefix", mutationName: "updateBulkControl", expected: &mutationName{object: "Control", operation: "update"}},
+		{name: "bulkUpdate prefix", mutationName: "bulkUpdateControl", expected: &mutationName{object: "Control", operation: "update"}},
+		{name: "deleteBulk prefix", mutationName: "deleteBulkControl", expected: &mutationName{object: "Control", operation: "delete"}},
+		{name: "bulkDelete prefix", mutationName: "bulkDeleteControl", expected: &mutationName{object: "Control", operation: "delete"}},
+		{name: "upsertBulk prefix", mutationName: "upsertBulkControl", expected: &mutationName{object: "Control", operation: "upsert"}},
+		{name: "bulkUpsert prefix", mutationName: "bulkUpsertControl", expected: &mutationName{object: "Control", operation: "upsert"}},
+		{name: "updateBulkCSV prefix", mutationName: "updateBulkCSVControl", expected: &mutationName{object: "Control", operation: "update", csv: true}},
+		{name: "updateBulkCSV prefix with longer name", mutationName: "updateBulkCSVRiskAssessment", expected: &mutationName{object: "RiskAssessment", operation: "update", csv: true}},
+		{name: "bulkCSVUpdate prefix", mutationName: "bulkCSVUpdatePolicy", expected: &mutationName{object: "Policy", operation: "update", csv: true}},
+		{name: "csvBulkUpdate prefix", mutationName: "csvBulkUpdateUser", expected: &mutationName{object: "User", operation: "update", csv: true}},
+		{name: "createBulkCSV prefix", mutationName: "createBulkCSVControl", expected: &mutationName{object: "Control", operation: "create", csv: true}},
+		{name: "bulkCSVCreate prefix", mutationName: "bulkCSVCreatePolicy", expected: &mutationName{object: "Policy", operation: "create", csv: true}},
+		{name: "csvBulkCreate prefix", mutationName: "csvBulkCreateUser", expected: &mutationName{object: "User", operation: "create", csv: true}},
+		{name: "object name starting with an operation", mutationName: "createBulkCSVUpdateLog", expected: &mutationName{object: "UpdateLog", operation: "create", csv: true}},
+		{name: "case insensitive matching", mutationName: "updatebulkcsvcontrol", expected: &mutationName{object: "control", operation: "update", csv: true}},
+		{name: "mixed case CSV variation", mutationName: "CreateBulkCsvControl", expected: &mutationName{object: "Control", operation: "create", csv: true}},
+		{name: "createOrUpdateBulkCSV prefix", mutationName: "createOrUpdateBulkCSVControl", expected: &mutationName{object: "Control", operation: "upsert", csv: true}},
+		{name: "upsertBulkCSV prefix", mutationName: "upsertBulkCSVPolicy", expected: &mutationName{object: "Policy", operation: "upsert", csv: true}},
+		{name: "bulkCSVUpsert prefix", mutationName: "BulkCSVUpsertRisk", expected: &mutationName{object: "Risk", operation: "upsert", csv: true}},
+		{name: "no matching prefix", mutationName: "createUser"},
+		{name: "upsert without bulk", mutationName: "upsertControl"},
+		{name: "other bulk operation", mutationName: "archiveBulkControl"},
+		{name: "empty string", mutationName: ""},
+		{name: "prefix without object", mutationName: "createBulk"},
+		{name: "pattern in middle of name", mutationName: "doSomeUpdateBulkCSVProcessing"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched, ok, err := matchMutationName(patterns, tc.mutationName)
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				assert.False(t, ok)
+
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tc.expected.object, matched.object)
+			assert.Equal(t, tc.expected.operation, matched.operation)
+			assert.Equal(t, tc.expected.csv, matched.csv)
+		})
+	}
+}
+
+func TestMatchCustomMutationNames(t *testing.T) {
+	patterns, err := parseMutationNamePatterns([]string{"{op}Many{Entity}", "{entity}BatchCreate", "{entity}Batch{Op}FromCSV"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		mutationName string
+		expected     *mutationName
+	}{
+		{mutationName: "createManyControl", expected: &mutationName{object: "Control", operation: "create", pattern: "{op}Many{Entity}"}},
+		{mutationName: "deleteManyInternalPolicy", expected: &mutationName{object: "InternalPolicy", operation: "delete", pattern: "{op}Many{Entity}"}},
+		{mutationName: "createOrUpdateManyControl", expected: &mutationName{object: "Control", operation: "upsert", pattern: "{op}Many{Entity}"}},
+		{mutationName: "controlBatchCreate", expected: &mutationName{object: "Control", operation: "create", pattern: "{entity}BatchCreate"}},
+		{mutationName: "internalPolicyBatchUpdateFromCSV", expected: &mutationName{object: "InternalPolicy", operation: "update", csv: true, pattern: "{entity}Batch{Op}FromCSV"}},
+		{mutationName: "createBulkControl"},
+		{mutationName: "controlBatchUpdate"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.mutationName, func(t *testing.T) {
+			matched, ok, err := matchMutationName(patterns, tc.mutationName)
+			require.NoError(t, err)
+
+			if tc.expected == nil {
+				assert.False(t, ok)
+
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, *tc.expected, matched)
+		})
+	}
+}
+
+func TestParseMutationNamePatterns(t *testing.T) {
+	testCases := []struct {
+		name        string
+		patterns    []string
+		expectedErr string
+	}{
+		{name: "default patterns", patterns: DefaultMutationNamePatterns},
+		{name: "duplicate patterns", patterns: []string{"{op}Many{Entity}", "{op}Many{Entity}"}},
+		{name: "operation in the text", patterns: []string{"{entity}CreateOrUpdateBatch"}},
+		{name: "missing entity", patterns: []string{"{op}Many"}, expectedErr: `"{op}Many" must have one {entity} placeholder`},
+		{name: "two entities", patterns: []string{"{op}{Entity}To{Entity}"}, expectedErr: "must have one {entity} placeholder"},
+		{name: "two operations", patterns: []string{"{op}{Entity}{op}"}, expectedErr: "must have at most one {op} placeholder"},
+		{name: "unknown placeholder", patterns: []string{"{op}Many{Object}"}, expectedErr: "has unknown placeholder {Object}"},
+		{name: "no operation", patterns: []string{"{entity}Batch"}, expectedErr: "must have an {op} placeholder"},
+		{name: "two operations in the text", patterns: []string{"{entity}CreateAndDelete"}, expectedErr: "must have an {op} placeholder"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseMutationNamePatterns(tc.patterns)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidMutationNamePattern)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestMatchMutationNameAmbiguous(t *testing.T) {
+	patterns, err := parseMutationNamePatterns([]string{"{op}Many{Entity}", "createMany{Entity}", "{op}ManyCSV{Entity}", "{op}ManyCsv{Entity}"})
+	require.NoError(t, err)
+
+	_, _, err = matchMutationName(patterns, "createManyControl")
+	require.ErrorIs(t, err, ErrAmbiguousMutationName)
+	assert.ErrorContains(t, err, `createManyControl matches the patterns "{op}Many{Entity}", "createMany{Entity}"`)
+
+	// the CSV patterns take precedence, but only one of them can match
+	_, _, err = matchMutationName(patterns, "updateManyCSVControl")
+	require.ErrorIs(t, err, ErrAmbiguousMutationName)
+
+	matched, ok, err := matchMutationName(patterns, "deleteManyControl")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "delete", matched.operation)
+}
+
+func TestMutationNameMatcher(t *testing.T) {
+	matcher, err := NewMutationNameMatcher()
+	require.NoError(t, err)
+
+	matched, ok, err := matcher.Match("createBulkCSVControl")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, MutationName{Object: "Control", Operation: "create", CSV: true}, matched)
+
+	matcher, err = NewMutationNameMatcher("{op}Many{Entity}", "{entity}BatchCreate")
+	require.NoError(t, err)
+
+	matched, ok, err = matcher.Match("controlBatchCreate")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, MutationName{Object: "Control", Operation: "create"}, matched)
+
+	// the default patterns are replaced
+	_, ok, err = matcher.Match("createBulkControl")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewMutationNameMatcher("{op}Many")
+	require.ErrorIs(t, err, ErrInvalidMutationNamePattern)
+}
+
+func TestGenerateCodeMutationNamePatterns(t *testing.T) {
+	newData := func(fields ...string) *codegen.Data {
+		mutation := &ast.Definition{Name: "Mutation"}
+		for _, name := range fields {
+			mutation.Fields = append(mutation.Fields, &ast.FieldDefinition{Name: name})
+		}
+
+		return &codegen.Data{
+			Config: &config.Config{
+				Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: t.TempDir()},
+			},
+			Schema: &ast.Schema{Mutation: mutation, Types: map[string]*ast.Definition{}},
+		}
+	}
+
+	t.Run("custom patterns", func(t *testing.T) {
+		report := genreport.New()
+		plugin := NewWithOptions(WithDryRun(report), WithMutationNamePatterns("{op}Many{Entity}", "{entity}BatchCreate", "{op}ManyFromCSV{Entity}"))
+
+		data := newData("createManyControl", "updateManyControl", "updateManyFromCSVControl", "policyBatchCreate", "createBulkRisk")
+		require.NoError(t, plugin.GenerateCode(data))
+
+		operations := map[string]bool{}
+		for _, object := range report.Bulk {
+			operations[object.Object+"/"+object.Operation] = object.HasCSVUpdateMutation
+		}
+
+		assert.Equal(t, map[string]bool{
+			"Control/create": true,
+			"Control/update": true,
+			"Policy/create":  false,
+		}, operations)
+	})
+
+	t.Run("ambiguous mutation", func(t *testing.T) {
+		plugin := NewWithOptions(WithDryRun(genreport.New()), WithMutationNamePatterns("{op}Many{Entity}", "createMany{Entity}"))
+
+		err := plugin.GenerateCode(newData("createManyControl", "deleteManyControl"))
+		require.ErrorIs(t, err, ErrAmbiguousMutationName)
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		plugin := NewWithOptions(WithDryRun(genreport.New()), WithMutationNamePatterns("{op}Many"))
+
+		err := plugin.GenerateCode(newData("createManyControl"))
+		require.ErrorIs(t, err, ErrInvalidMutationNamePattern)
+	})
+}
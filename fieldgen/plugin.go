@@ -3,12 +3,17 @@ package fieldgen
 import (
 	"github.com/99designs/gqlgen/codegen/config"
 	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
 )
 
 // ExtraFields is a struct to hold the additional fields to add to the schema
 type ExtraFields struct {
 	// FieldDefs is a list of additional fields to add to the schema
 	FieldDefs []AdditionalField
+	// DryRunReport when set records each field added to the schema, fieldgen only changes the
+	// in-memory schema so the fields are still added for the other plugins to use
+	DryRunReport *genreport.Report
 }
 
 // AdditionalField is a struct to with details about the additional field to add to the schema
@@ -41,14 +46,14 @@ func NewExtraFieldsGen(fields []AdditionalField) *ExtraFields {
 }
 
 // Name returns the plugin name
-func (f *ExtraFields) Name() string {
+func (fg *ExtraFields) Name() string {
 	return "fieldgen"
 }
 
 // MutateConfig satisfies the plugin interface
-func (f *ExtraFields) MutateConfig(cfg *config.Config) error {
+func (fg *ExtraFields) MutateConfig(cfg *config.Config) error {
 	for i, t := range cfg.Schema.Types {
-		for _, f := range f.FieldDefs {
+		for _, f := range fg.FieldDefs {
 			fieldType := f.Type
 
 			if f.CustomType != "" {
@@ -72,6 +77,8 @@ func (f *ExtraFields) MutateConfig(cfg *config.Config) error {
 
 					src := createAdditionalSource(t.Name, f.Name, fieldType)
 					cfg.Sources = append(cfg.Sources, src)
+
+					fg.report(t.Name, newField, src)
 				}
 			}
 
@@ -85,6 +92,8 @@ func (f *ExtraFields) MutateConfig(cfg *config.Config) error {
 
 					src := createAdditionalSource(t.Name, f.Name, fieldType)
 					cfg.Sources = append(cfg.Sources, src)
+
+					fg.report(t.Name, newField, src)
 				}
 			}
 		}
@@ -92,3 +101,18 @@ func (f *ExtraFields) MutateConfig(cfg *config.Config) error {
 
 	return nil
 }
+
+// report adds the field added to the schema type to the dry run report
+func (fg *ExtraFields) report(typeName string, field *ast.FieldDefinition, src *ast.Source) {
+	if fg.DryRunReport == nil {
+		return
+	}
+
+	fg.DryRunReport.AddFieldExtension(genreport.FieldExtension{
+		Type:      typeName,
+		Field:     field.Name,
+		FieldType: field.Type.NamedType,
+		NonNull:   field.Type.NonNull,
+		Source:    src.Name,
+	})
+}
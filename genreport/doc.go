@@ -0,0 +1,2 @@
+// Package genreport provides the machine readable report of what the plugins generate, used for dry runs
+package genreport
@@ -0,0 +1,199 @@
+package genreport
+
+import (
+	"cmp"
+	"encoding/json"
+	"io"
+	"slices"
+	"sync"
+)
+
+// Report collects what each plugin generates, or would generate during a dry run.
+// A single report can be shared by all the plugins and written once generation completes
+type Report struct {
+	mu sync.Mutex
+
+	// Resolvers are the resolver fields implemented by resolvergen
+	Resolvers []Resolver `json:"resolvers"`
+	// Bulk are the bulk objects and operations generated by bulkgen
+	Bulk []BulkObject `json:"bulk"`
+	// Search are the searchable objects generated by searchgen
+	Search []SearchObject `json:"search"`
+	// Fields are the schema extensions added by fieldgen
+	Fields []FieldExtension `json:"fields"`
+	// Warnings are the generation warnings, such as ambiguous resolver field names
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Resolver is the classification of a single resolver field
+type Resolver struct {
+	// Object is the graphql object the field belongs to, e.g. Mutation
+	Object string `json:"object"`
+	// Field is the go name of the resolver field, e.g. CreateTask
+	Field string `json:"field"`
+	// Entity is the schema name the resolver operates on, e.g. Task
+	Entity string `json:"entity,omitempty"`
+	// Operation is the operation the field was classified into, e.g. Create, empty when the field is not implemented
+	Operation string `json:"operation,omitempty"`
+	// Template is the template used to implement the field, e.g. create.gotpl
+	Template string `json:"template,omitempty"`
+	// FieldTemplate is the defined template used for custom update and delete fields, e.g. revisionBump
+	FieldTemplate string `json:"fieldTemplate,omitempty"`
+	// Directive is true when the operation was set with the crud directive instead of the field name
+	Directive bool `json:"directive,omitempty"`
+}
+
+// BulkObject is a single bulk operation for an object
+type BulkObject struct {
+	// Object is the schema name, e.g. Task
+	Object string `json:"object"`
+	// Mutation is the name of the bulk mutation or CSV export query, e.g. createBulkTask
+	Mutation string `json:"mutation"`
+	// Operation is the bulk operation type, e.g. create
+	Operation string `json:"operation"`
+	// Fields are the input fields of the object
+	Fields []string `json:"fields,omitempty"`
+	// RequiredFields are the input fields that must be set in each row of the sample CSV
+	RequiredFields []string `json:"requiredFields,omitempty"`
+	// CSVColumns are the custom CSV columns mapped to fields of the object
+	CSVColumns []string `json:"csvColumns,omitempty"`
+	// HasCSVUpdateMutation is true when the object has a CSV bulk update mutation
+	HasCSVUpdateMutation bool `json:"hasCSVUpdateMutation,omitempty"`
+	// Atomic is true when the bulk operation is all-or-nothing
+	Atomic bool `json:"atomic,omitempty"`
+	// UpsertKey is the natural key used by the bulk upsert to match rows to existing objects
+	UpsertKey string `json:"upsertKey,omitempty"`
+	// UpdateWorkers is the max number of concurrent updates of the bulk update, 0 when updated serially
+	UpdateWorkers int `json:"updateWorkers,omitempty"`
+	// DryRun is true when a bulk or CSV mutation of the operation can validate the rows without saving them
+	DryRun bool `json:"dryRun,omitempty"`
+	// BulkJob is true when a job variant of the CSV mutation of the operation saves the rows in the background
+	BulkJob bool `json:"bulkJob,omitempty"`
+	// SoftDelete is true when the bulk delete sets the deleted_at time instead of removing the objects
+	SoftDelete bool `json:"softDelete,omitempty"`
+	// AuthzFields are the owner and parent id fields checked before any row of a bulk create is created
+	AuthzFields []string `json:"authzFields,omitempty"`
+	// SampleCSV is the path of the sample CSV written for the object
+	SampleCSV string `json:"sampleCSV,omitempty"`
+}
+
+// SearchObject is a single searchable object
+type SearchObject struct {
+	// Object is the schema name, e.g. Task
+	Object string `json:"object"`
+	// Fields are the searchable fields
+	Fields []string `json:"fields"`
+	// AdminFields are the fields only searchable by system admins
+	AdminFields []string `json:"adminFields,omitempty"`
+}
+
+// FieldExtension is a single field added to a schema type
+type FieldExtension struct {
+	// Type is the schema type the field is added to, e.g. Task
+	Type string `json:"type"`
+	// Field is the name of the added field
+	Field string `json:"field"`
+	// FieldType is the graphql type of the added field
+	FieldType string `json:"fieldType"`
+	// NonNull is true when the field is required
+	NonNull bool `json:"nonNull,omitempty"`
+	// Source is the name of the schema source created for the field
+	Source string `json:"source"`
+}
+
+// New returns a new empty report
+func New() *Report {
+	return &Report{}
+}
+
+// AddResolver adds resolver fields to the report
+func (r *Report) AddResolver(resolvers ...Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Resolvers = append(r.Resolvers, resolvers...)
+}
+
+// AddBulkObject adds bulk objects to the report
+func (r *Report) AddBulkObject(objects ...BulkObject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Bulk = append(r.Bulk, objects...)
+}
+
+// AddSearchObject adds searchable objects to the report
+func (r *Report) AddSearchObject(objects ...SearchObject) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Search = append(r.Search, objects...)
+}
+
+// AddFieldExtension adds schema extensions to the report
+func (r *Report) AddFieldExtension(fields ...FieldExtension) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Fields = append(r.Fields, fields...)
+}
+
+// AddWarning adds generation warnings to the report
+func (r *Report) AddWarning(warnings ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Warnings = append(r.Warnings, warnings...)
+}
+
+// WriteJSON writes the report as indented JSON, the entries are sorted so the output
+// is stable between runs and can be diffed when the schema changes
+func (r *Report) WriteJSON(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sort()
+
+	// write empty lists instead of null so consumers don't need to handle both
+	if r.Resolvers == nil {
+		r.Resolvers = []Resolver{}
+	}
+
+	if r.Bulk == nil {
+		r.Bulk = []BulkObject{}
+	}
+
+	if r.Search == nil {
+		r.Search = []SearchObject{}
+	}
+
+	if r.Fields == nil {
+		r.Fields = []FieldExtension{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(r)
+}
+
+// sort sorts all the entries of the report
+func (r *Report) sort() {
+	slices.SortStableFunc(r.Resolvers, func(a, b Resolver) int {
+		return cmp.Or(cmp.Compare(a.Object, b.Object), cmp.Compare(a.Field, b.Field))
+	})
+
+	slices.SortStableFunc(r.Bulk, func(a, b BulkObject) int {
+		return cmp.Or(cmp.Compare(a.Object, b.Object), cmp.Compare(a.Operation, b.Operation), cmp.Compare(a.Mutation, b.Mutation))
+	})
+
+	slices.SortStableFunc(r.Search, func(a, b SearchObject) int {
+		return cmp.Compare(a.Object, b.Object)
+	})
+
+	slices.SortStableFunc(r.Fields, func(a, b FieldExtension) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Field, b.Field))
+	})
+
+	slices.Sort(r.Warnings)
+}
@@ -0,0 +1,44 @@
+package genreport
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	report := New()
+
+	report.AddResolver(
+		Resolver{Object: "Mutation", Field: "UpdateTask", Entity: "Task", Operation: "Update", Template: "update.gotpl"},
+		Resolver{Object: "Mutation", Field: "CreateTask", Entity: "Task", Operation: "Create", Template: "create.gotpl"},
+	)
+	report.AddBulkObject(BulkObject{Object: "Task", Mutation: "createBulkTask", Operation: "create"})
+	report.AddSearchObject(SearchObject{Object: "Task", Fields: []string{"title"}})
+	report.AddFieldExtension(FieldExtension{Type: "Task", Field: "extra", FieldType: "String", Source: "extra.graphql"})
+	report.AddWarning("b warning", "a warning")
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+
+	var out Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+
+	require.Len(t, out.Resolvers, 2)
+	assert.Equal(t, "CreateTask", out.Resolvers[0].Field)
+	assert.Equal(t, "UpdateTask", out.Resolvers[1].Field)
+	assert.Equal(t, []string{"a warning", "b warning"}, out.Warnings)
+	assert.Len(t, out.Bulk, 1)
+	assert.Len(t, out.Search, 1)
+	assert.Len(t, out.Fields, 1)
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New().WriteJSON(&buf))
+
+	assert.JSONEq(t, `{"resolvers":[],"bulk":[],"search":[],"fields":[]}`, buf.String())
+}
@@ -0,0 +1,14 @@
+package graphutils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotArchived is returned by the generated unarchive resolvers when the object does not have the archived status
+var ErrNotArchived = errors.New("object is not archived")
+
+// NewNotArchivedError returns an ErrNotArchived error with the object and id that was not archived
+func NewNotArchivedError(object, id string) error {
+	return fmt.Errorf("%s %s: %w", object, id, ErrNotArchived)
+}
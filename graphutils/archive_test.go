@@ -0,0 +1,14 @@
+package graphutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNotArchivedError(t *testing.T) {
+	err := NewNotArchivedError("control", "01HX")
+
+	assert.ErrorIs(t, err, ErrNotArchived)
+	assert.Equal(t, "control 01HX: object is not archived", err.Error())
+}
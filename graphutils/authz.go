@@ -0,0 +1,70 @@
+package graphutils
+
+import (
+	"maps"
+	"slices"
+)
+
+// BulkRowIDs maps the ids checked by the authorization pre-pass of a bulk operation to the rows they were read from,
+// e.g. the id of each row of a CSV bulk update or the owner id of each row of a bulk create, so the rows with an id
+// the user is not allowed to use can be reported before any row is saved
+type BulkRowIDs struct {
+	ids  []string
+	rows map[string][]int
+}
+
+// NewBulkRowIDs returns an empty BulkRowIDs, the ids of the rows are added with Add
+func NewBulkRowIDs() *BulkRowIDs {
+	return &BulkRowIDs{
+		rows: map[string][]int{},
+	}
+}
+
+// Add records the id of the row at the index of the request, empty ids are not checked
+func (b *BulkRowIDs) Add(index int, id string) {
+	if id == "" {
+		return
+	}
+
+	if _, ok := b.rows[id]; !ok {
+		b.ids = append(b.ids, id)
+	}
+
+	b.rows[id] = append(b.rows[id], index)
+}
+
+// IDs returns the unique ids of the rows to check, in the order they were added
+func (b *BulkRowIDs) IDs() []string {
+	return b.ids
+}
+
+// Denied returns the index of each row with an id that is not in the authorized ids
+func (b *BulkRowIDs) Denied(authorized []string) map[int]struct{} {
+	allowed := make(map[string]struct{}, len(authorized))
+	for _, id := range authorized {
+		allowed[id] = struct{}{}
+	}
+
+	denied := map[int]struct{}{}
+
+	for id, rows := range b.rows {
+		if _, ok := allowed[id]; ok {
+			continue
+		}
+
+		for _, i := range rows {
+			denied[i] = struct{}{}
+		}
+	}
+
+	return denied
+}
+
+// DeniedRows returns the index of each denied row in order, e.g. to report the rows of a bulk create
+// that were not created in the payload, no denied rows return an empty list
+func DeniedRows(denied map[int]struct{}) []int {
+	rows := slices.AppendSeq(make([]int, 0, len(denied)), maps.Keys(denied))
+	slices.Sort(rows)
+
+	return rows
+}
@@ -0,0 +1,61 @@
+package graphutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkRowIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		ids        []string
+		authorized []string
+		checked    []string
+		denied     map[int]struct{}
+	}{
+		{
+			name:       "all rows authorized",
+			ids:        []string{"01HX", "01HY"},
+			authorized: []string{"01HY", "01HX"},
+			checked:    []string{"01HX", "01HY"},
+			denied:     map[int]struct{}{},
+		},
+		{
+			name:       "denied id on several rows",
+			ids:        []string{"01HX", "01HY", "01HX"},
+			authorized: []string{"01HY"},
+			checked:    []string{"01HX", "01HY"},
+			denied:     map[int]struct{}{0: {}, 2: {}},
+		},
+		{
+			name:       "rows without an id are not checked",
+			ids:        []string{"", "01HY", ""},
+			authorized: nil,
+			checked:    []string{"01HY"},
+			denied:     map[int]struct{}{1: {}},
+		},
+		{
+			name:    "no rows",
+			checked: nil,
+			denied:  map[int]struct{}{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rows := NewBulkRowIDs()
+			for i, id := range tc.ids {
+				rows.Add(i, id)
+			}
+
+			assert.Equal(t, tc.checked, rows.IDs())
+			assert.Equal(t, tc.denied, rows.Denied(tc.authorized))
+		})
+	}
+}
+
+func TestDeniedRows(t *testing.T) {
+	assert.Equal(t, []int{0, 2, 5}, DeniedRows(map[int]struct{}{5: {}, 0: {}, 2: {}}))
+	assert.Equal(t, []int{}, DeniedRows(map[int]struct{}{}))
+}
@@ -0,0 +1,237 @@
+package graphutils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrBulkOperationFailed is returned by atomic bulk operations when any item fails and the batch is rolled back
+	ErrBulkOperationFailed = errors.New("bulk operation failed, no changes were made")
+	// ErrInvalidBulkItemStatus is returned when a bulk item status is not SUCCESS or FAILED
+	ErrInvalidBulkItemStatus = errors.New("invalid bulk item status")
+	// ErrBulkDryRunAborted is the error of the rows of a bulk dry run that were not checked because an earlier row
+	// aborted the dry run transaction
+	ErrBulkDryRunAborted = errors.New("row was not checked, an earlier row aborted the dry run")
+)
+
+const (
+	// bulkOperationFailedCode is the code added to the graphql error extensions for bulk errors
+	bulkOperationFailedCode = "BULK_OPERATION_FAILED"
+	// bulkItemFailedCode is the code of a failed bulk item when the error does not have its own code
+	bulkItemFailedCode = "BULK_ITEM_FAILED"
+)
+
+// BulkItemStatus is the status of a single item of a bulk operation
+type BulkItemStatus string
+
+const (
+	// BulkItemStatusSuccess is the status of an item that was created, updated or deleted
+	BulkItemStatusSuccess BulkItemStatus = "SUCCESS"
+	// BulkItemStatusFailed is the status of an item that was not changed
+	BulkItemStatusFailed BulkItemStatus = "FAILED"
+)
+
+// MarshalGQL implements the graphql.Marshaler interface so the type can be bound to a graphql enum
+func (s BulkItemStatus) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(s)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface so the type can be bound to a graphql enum
+func (s *BulkItemStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrInvalidBulkItemStatus, v)
+	}
+
+	switch status := BulkItemStatus(str); status {
+	case BulkItemStatusSuccess, BulkItemStatusFailed:
+		*s = status
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidBulkItemStatus, str)
+	}
+}
+
+// BulkItemResult is the result of a single item of a bulk operation, it is returned in the results
+// of the bulk payloads so clients can tell which rows failed and why
+type BulkItemResult struct {
+	// Index is the position of the item in the ids or input rows of the request
+	Index int `json:"index"`
+	// ID is the id of the item, empty for rows that failed before they were created
+	ID string `json:"id,omitempty"`
+	// Status is the status of the item
+	Status BulkItemStatus `json:"status"`
+	// Code is the error code of a failed item, taken from the error extensions when available
+	Code string `json:"code,omitempty"`
+	// Message is the error message of a failed item
+	Message string `json:"message,omitempty"`
+}
+
+// NewBulkItemSuccess returns the result of an item that succeeded
+func NewBulkItemSuccess(index int, id string) *BulkItemResult {
+	return &BulkItemResult{
+		Index:  index,
+		ID:     id,
+		Status: BulkItemStatusSuccess,
+	}
+}
+
+// NewBulkItemFailure returns the result of an item that failed with the code and message of the error
+func NewBulkItemFailure(index int, id string, err error) *BulkItemResult {
+	return &BulkItemResult{
+		Index:   index,
+		ID:      id,
+		Status:  BulkItemStatusFailed,
+		Code:    bulkItemCode(err),
+		Message: err.Error(),
+	}
+}
+
+// BulkItemSuccesses returns a successful result for each of the ids in the request
+func BulkItemSuccesses(ids []string) []*BulkItemResult {
+	results := make([]*BulkItemResult, 0, len(ids))
+	for i, id := range ids {
+		results = append(results, NewBulkItemSuccess(i, id))
+	}
+
+	return results
+}
+
+// BulkItemResults returns a result for each of the ids in the request keyed by the index of the id, so an id that
+// is in the request more than once has a result for each row: indexes in succeeded are successful, indexes in
+// failures failed with their error and any other index failed with the fallback error, e.g. because the id was
+// filtered out by the authorization check before it was processed
+func BulkItemResults(ids []string, succeeded map[int]struct{}, failures map[int]error, fallback error) []*BulkItemResult {
+	results := make([]*BulkItemResult, 0, len(ids))
+
+	for i, id := range ids {
+		if _, ok := succeeded[i]; ok {
+			results = append(results, NewBulkItemSuccess(i, id))
+
+			continue
+		}
+
+		err, ok := failures[i]
+		if !ok {
+			err = fallback
+		}
+
+		results = append(results, NewBulkItemFailure(i, id, err))
+	}
+
+	return results
+}
+
+// BulkDryRunResults returns a result for each of the n rows of a bulk dry run in the order of the request, from the
+// rows that succeeded and the rows that failed in the bulk error. A row without a result was not checked because an
+// earlier row aborted the dry run transaction, it fails with ErrBulkDryRunAborted
+func BulkDryRunResults(n int, succeeded []*BulkItemResult, failed *BulkError) []*BulkItemResult {
+	results := make([]*BulkItemResult, n)
+
+	for _, r := range slices.Concat(succeeded, failed.Results) {
+		if r.Index >= 0 && r.Index < n {
+			results[r.Index] = r
+		}
+	}
+
+	for i, r := range results {
+		if r == nil {
+			results[i] = NewBulkItemFailure(i, "", ErrBulkDryRunAborted)
+		}
+	}
+
+	return results
+}
+
+// label returns the id of the item, or its row when it does not have an id
+func (r *BulkItemResult) label() string {
+	if r.ID != "" {
+		return r.ID
+	}
+
+	return "row " + strconv.Itoa(r.Index)
+}
+
+// bulkItemCode returns the code from the graphql error extensions of the error, or the generic
+// bulk item code when the error does not have one
+func bulkItemCode(err error) string {
+	var extErr interface{ Extensions() map[string]any }
+	if errors.As(err, &extErr) {
+		if code, ok := extErr.Extensions()["code"].(string); ok && code != "" {
+			return code
+		}
+	}
+
+	return bulkItemFailedCode
+}
+
+// BulkError is returned by the generated atomic bulk resolvers with the items that failed, the request
+// transaction is rolled back so none of the items in the batch are changed
+type BulkError struct {
+	// Object is the name of the object of the bulk operation, e.g. control
+	Object string
+	// Operation is the bulk operation, e.g. update or delete
+	Operation string
+	// FailedIDs are the ids that failed, in the order they were processed
+	FailedIDs []string
+	// Errors are the underlying errors for each failed item
+	Errors []error
+	// Results are the results of the failed items with their index, code and message
+	Results []*BulkItemResult
+}
+
+// NewBulkError returns an empty BulkError for the object and operation, failures are added with Add
+func NewBulkError(object, operation string) *BulkError {
+	return &BulkError{
+		Object:    object,
+		Operation: operation,
+	}
+}
+
+// Add records the failure of the item at the index of the request, the id is empty for input rows
+// that failed before they were created
+func (e *BulkError) Add(index int, id string, err error) {
+	if id != "" {
+		e.FailedIDs = append(e.FailedIDs, id)
+	}
+
+	e.Errors = append(e.Errors, err)
+	e.Results = append(e.Results, NewBulkItemFailure(index, id, err))
+}
+
+// HasErrors returns true when any item failed
+func (e *BulkError) HasErrors() bool {
+	return len(e.Results) > 0
+}
+
+// Error returns the error message with the failed items
+func (e *BulkError) Error() string {
+	failed := make([]string, 0, len(e.Results))
+	for _, r := range e.Results {
+		failed = append(failed, r.label())
+	}
+
+	return fmt.Sprintf("bulk %s %s: %v, failed items: %s", e.Operation, e.Object, ErrBulkOperationFailed, strings.Join(failed, ", "))
+}
+
+// Unwrap returns ErrBulkOperationFailed and the errors of the failed items so they can be checked with errors.Is
+func (e *BulkError) Unwrap() []error {
+	return append([]error{ErrBulkOperationFailed}, e.Errors...)
+}
+
+// Extensions returns the graphql error extensions with the failed ids and the result of each failed item
+func (e *BulkError) Extensions() map[string]any {
+	return map[string]any{
+		"code":      bulkOperationFailedCode,
+		"object":    e.Object,
+		"operation": e.Operation,
+		"failedIDs": e.FailedIDs,
+		"results":   e.Results,
+	}
+}
@@ -0,0 +1,99 @@
+package graphutils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// codedError is an error with graphql error extensions
+type codedError struct{}
+
+func (codedError) Error() string { return "you are not authorized to perform this action" }
+
+func (codedError) Extensions() map[string]any { return map[string]any{"code": "UNAUTHORIZED"} }
+
+func TestBulkError(t *testing.T) {
+	errNotFound := errors.New("not found") //nolint:err113
+
+	bulkErr := NewBulkError("control", "update")
+	assert.False(t, bulkErr.HasErrors())
+
+	bulkErr.Add(0, "01HX", errNotFound)
+	bulkErr.Add(2, "", errNotFound)
+	require.True(t, bulkErr.HasErrors())
+
+	var err error = bulkErr
+
+	assert.ErrorIs(t, err, ErrBulkOperationFailed)
+	assert.ErrorIs(t, err, errNotFound)
+	assert.Equal(t, "bulk update control: bulk operation failed, no changes were made, failed items: 01HX, row 2", err.Error())
+	assert.Equal(t, []string{"01HX"}, bulkErr.Extensions()["failedIDs"])
+	assert.Equal(t, "BULK_OPERATION_FAILED", bulkErr.Extensions()["code"])
+	assert.Equal(t, []*BulkItemResult{
+		{Index: 0, ID: "01HX", Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "not found"},
+		{Index: 2, Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "not found"},
+	}, bulkErr.Extensions()["results"])
+}
+
+func TestBulkItemResults(t *testing.T) {
+	errNotFound := errors.New("not found") //nolint:err113
+
+	results := BulkItemResults(
+		[]string{"01HX", "01HY", "01HZ", "01HX"},
+		map[int]struct{}{0: {}},
+		map[int]error{1: errNotFound, 3: errNotFound},
+		codedError{},
+	)
+
+	assert.Equal(t, []*BulkItemResult{
+		{Index: 0, ID: "01HX", Status: BulkItemStatusSuccess},
+		{Index: 1, ID: "01HY", Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "not found"},
+		{Index: 2, ID: "01HZ", Status: BulkItemStatusFailed, Code: "UNAUTHORIZED", Message: "you are not authorized to perform this action"},
+		// the same id on another row has its own result
+		{Index: 3, ID: "01HX", Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "not found"},
+	}, results)
+}
+
+func TestBulkItemStatusGQL(t *testing.T) {
+	var buf bytes.Buffer
+
+	BulkItemStatusFailed.MarshalGQL(&buf)
+	assert.Equal(t, `"FAILED"`, buf.String())
+
+	var status BulkItemStatus
+
+	require.NoError(t, status.UnmarshalGQL("SUCCESS"))
+	assert.Equal(t, BulkItemStatusSuccess, status)
+
+	require.ErrorIs(t, status.UnmarshalGQL("PENDING"), ErrInvalidBulkItemStatus)
+	require.ErrorIs(t, status.UnmarshalGQL(1), ErrInvalidBulkItemStatus)
+}
+
+func TestBulkItemSuccesses(t *testing.T) {
+	assert.Equal(t, []*BulkItemResult{
+		{Index: 0, ID: "01HX", Status: BulkItemStatusSuccess},
+		{Index: 1, ID: "01HY", Status: BulkItemStatusSuccess},
+	}, BulkItemSuccesses([]string{"01HX", "01HY"}))
+}
+
+func TestBulkDryRunResults(t *testing.T) {
+	errNotFound := errors.New("not found") //nolint:err113
+
+	bulkErr := NewBulkError("control", "update")
+	bulkErr.Add(2, "01HZ", errNotFound)
+	bulkErr.Add(1, "01HY", codedError{})
+
+	results := BulkDryRunResults(4, []*BulkItemResult{NewBulkItemSuccess(0, "01HX")}, bulkErr)
+
+	assert.Equal(t, []*BulkItemResult{
+		{Index: 0, ID: "01HX", Status: BulkItemStatusSuccess},
+		{Index: 1, ID: "01HY", Status: BulkItemStatusFailed, Code: "UNAUTHORIZED", Message: "you are not authorized to perform this action"},
+		{Index: 2, ID: "01HZ", Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "not found"},
+		// the row after the row that aborted the dry run transaction was not checked
+		{Index: 3, Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: ErrBulkDryRunAborted.Error()},
+	}, results)
+}
@@ -0,0 +1,82 @@
+package graphutils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrConflict is returned when an update is made with a stale copy of the object
+var ErrConflict = errors.New("object was modified by another request")
+
+// conflictErrorCode is the code added to the graphql error extensions for conflict errors
+const conflictErrorCode = "CONFLICT"
+
+// ConflictError is returned by the generated update resolvers when the expected updated at time or version
+// in the request does not match the stored object, meaning the object was updated since it was read
+type ConflictError struct {
+	// Object is the name of the object being updated, e.g. control
+	Object string
+	// ID is the id of the object being updated
+	ID string
+	// Field is the field used for the check, e.g. updatedAt or version
+	Field string
+	// Expected is the value sent in the request
+	Expected any
+	// Actual is the value of the stored object
+	Actual any
+}
+
+// Error returns the error message with the expected and actual values
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %s: %v, expected %s %v but found %v", e.Object, e.ID, ErrConflict, e.Field, e.Expected, e.Actual)
+}
+
+// Unwrap returns ErrConflict so the error can be checked with errors.Is
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Extensions returns the graphql error extensions so clients can detect the conflict and reload the object
+func (e *ConflictError) Extensions() map[string]any {
+	return map[string]any{
+		"code":     conflictErrorCode,
+		"object":   e.Object,
+		"id":       e.ID,
+		"field":    e.Field,
+		"expected": e.Expected,
+		"actual":   e.Actual,
+	}
+}
+
+// CheckUpdatedAt returns a ConflictError when the expected updated at time is set and does not match the
+// updated at time of the stored object, a nil expected time skips the check
+func CheckUpdatedAt(object, id string, expected *time.Time, actual time.Time) error {
+	if expected == nil || expected.Equal(actual) {
+		return nil
+	}
+
+	return &ConflictError{
+		Object:   object,
+		ID:       id,
+		Field:    "updatedAt",
+		Expected: expected.Format(time.RFC3339Nano),
+		Actual:   actual.Format(time.RFC3339Nano),
+	}
+}
+
+// CheckVersion returns a ConflictError when the expected version is set and does not match the
+// version of the stored object, a nil expected version skips the check
+func CheckVersion[T comparable](object, id string, expected *T, actual T) error {
+	if expected == nil || *expected == actual {
+		return nil
+	}
+
+	return &ConflictError{
+		Object:   object,
+		ID:       id,
+		Field:    "version",
+		Expected: *expected,
+		Actual:   actual,
+	}
+}
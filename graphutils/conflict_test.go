@@ -0,0 +1,65 @@
+package graphutils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckUpdatedAt(t *testing.T) {
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name     string
+		expected *time.Time
+		conflict bool
+	}{
+		{
+			name:     "no expected time",
+			expected: nil,
+		},
+		{
+			name:     "matching time",
+			expected: lo.ToPtr(updatedAt),
+		},
+		{
+			name:     "matching time in another location",
+			expected: lo.ToPtr(updatedAt.In(time.FixedZone("EST", -5*60*60))),
+		},
+		{
+			name:     "stale time",
+			expected: lo.ToPtr(updatedAt.Add(-time.Minute)),
+			conflict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckUpdatedAt("control", "01HX", tt.expected, updatedAt)
+			if !tt.conflict {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrConflict)
+
+			var conflictErr *ConflictError
+			require.True(t, errors.As(err, &conflictErr))
+			assert.Equal(t, "updatedAt", conflictErr.Field)
+			assert.Equal(t, "CONFLICT", conflictErr.Extensions()["code"])
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	assert.NoError(t, CheckVersion[int]("control", "01HX", nil, 3))
+	assert.NoError(t, CheckVersion("control", "01HX", lo.ToPtr(3), 3))
+
+	err := CheckVersion("control", "01HX", lo.ToPtr(2), 3)
+	require.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "control 01HX: object was modified by another request, expected version 2 but found 3", err.Error())
+}
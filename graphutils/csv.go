@@ -0,0 +1,371 @@
+package graphutils
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCSVHeader is returned when the header of a CSV upload has unknown columns or is missing required columns
+var ErrInvalidCSVHeader = errors.New("invalid csv header")
+
+// invalidCSVHeaderCode is the code added to the graphql error extensions for invalid CSV headers
+const invalidCSVHeaderCode = "INVALID_CSV_HEADER"
+
+// CSVExportPageSize is the number of objects queried for each page of the generated CSV exports
+const CSVExportPageSize = 1000
+
+// FormatCSVValue returns the CSV cell of a field value of an exported object, nil values are empty,
+// times are formatted as RFC3339 and slices, maps and structs are written as JSON, the same format
+// used by the sample CSVs and parsed by the CSV bulk upload
+func FormatCSVValue(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+
+		rv = rv.Elem()
+	}
+
+	value := rv.Interface()
+
+	switch t := value.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format(time.RFC3339)
+	case []byte:
+		return string(t)
+	case fmt.Stringer:
+		return t.String()
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return ""
+		}
+
+		fallthrough
+	case reflect.Array, reflect.Struct:
+		b, err := json.Marshal(value)
+		if err != nil {
+			return ""
+		}
+
+		return string(b)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// WriteCSV writes the header and a record for each row to w, the cells of each record are
+// taken from the row by header name and columns missing from a row are left empty
+func WriteCSV(w io.Writer, header []string, rows []map[string]string) error {
+	writer, err := NewCSVExportWriter(w, header)
+	if err != nil {
+		return err
+	}
+
+	return writer.WritePage(rows)
+}
+
+// CSVExportWriter writes the pages of a CSV export as they are queried, so a large export
+// is never held in memory
+type CSVExportWriter struct {
+	writer *csv.Writer
+	header []string
+	record []string
+}
+
+// NewCSVExportWriter returns a CSVExportWriter that writes to w, the header is written immediately
+func NewCSVExportWriter(w io.Writer, header []string) (*CSVExportWriter, error) {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &CSVExportWriter{writer: writer, header: header, record: make([]string, len(header))}, nil
+}
+
+// WritePage writes a record for each row and flushes them to the underlying writer, the cells of
+// each record are taken from the row by header name and columns missing from a row are left empty
+func (e *CSVExportWriter) WritePage(rows []map[string]string) error {
+	for _, row := range rows {
+		for i, column := range e.header {
+			e.record[i] = row[column]
+		}
+
+		if err := e.writer.Write(e.record); err != nil {
+			return err
+		}
+	}
+
+	e.writer.Flush()
+
+	return e.writer.Error()
+}
+
+// CSVExportUploader stores the CSV export of the schema read from file and returns the reference the
+// export query responds with, e.g. the id or the download url of the stored file
+type CSVExportUploader func(ctx context.Context, schema string, file io.Reader) (string, error)
+
+// StreamCSVExport streams the CSV export written by write to upload, the pages are uploaded while the next
+// pages are queried. An error of write is returned over the error of the upload it interrupts
+func StreamCSVExport(ctx context.Context, schema string, header []string, write func(*CSVExportWriter) error, upload CSVExportUploader) (string, error) {
+	reader, pipe := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		writer, err := NewCSVExportWriter(pipe, header)
+		if err == nil {
+			err = write(writer)
+		}
+
+		// a nil error closes the pipe so the upload reads to the end of the export
+		pipe.CloseWithError(err)
+		done <- err
+	}()
+
+	ref, err := upload(ctx, schema, reader)
+
+	// stop the writer when the upload returns before reading the whole export
+	reader.Close()
+
+	writeErr := <-done
+
+	switch {
+	case writeErr != nil && !errors.Is(writeErr, io.ErrClosedPipe):
+		return "", writeErr
+	case err != nil:
+		return "", err
+	case writeErr != nil:
+		// the upload returned without reading the whole export
+		return "", writeErr
+	}
+
+	return ref, nil
+}
+
+// ReadCSVHeader reads the header of the CSV file and seeks back to the start of the file so it can still be
+// unmarshalled, an empty file has no header
+func ReadCSVHeader(file io.ReadSeeker) ([]string, error) {
+	header, err := csv.NewReader(file).Read()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// RequiredCSVHeaderMarker is appended to the headers of the required columns of the sample CSVs, e.g. RefCode*,
+// it is removed from the header of an upload before the header is checked and the rows are unmarshalled
+const RequiredCSVHeaderMarker = "*"
+
+// TrimCSVHeaderMarkers returns the CSV file with the required column markers removed from its header, so a
+// sample CSV can be uploaded without renaming its columns. The file is returned as is, seeked back to the
+// start, when its header has no markers
+func TrimCSVHeaderMarkers(file io.ReadSeeker) (io.ReadSeeker, error) {
+	header, err := ReadCSVHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.ContainsFunc(header, func(h string) bool { return strings.HasSuffix(h, RequiredCSVHeaderMarker) }) {
+		return file, nil
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, h := range records[0] {
+		records[0][i] = strings.TrimSuffix(h, RequiredCSVHeaderMarker)
+	}
+
+	var buf bytes.Buffer
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// UnknownCSVColumn is a column of a CSV upload that does not match any known header
+type UnknownCSVColumn struct {
+	// Header is the header of the column in the upload
+	Header string `json:"header"`
+	// Suggestion is the closest known header, empty when no known header is close enough
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// CSVHeaderError is returned when the header of a CSV upload has unknown columns, which would otherwise be
+// silently dropped when the rows are unmarshalled, or is missing required columns
+type CSVHeaderError struct {
+	// Object is the name of the object of the upload, e.g. control
+	Object string
+	// Unknown are the columns that do not match a known header, in the order of the upload
+	Unknown []UnknownCSVColumn
+	// Missing are the required columns that are not in the upload
+	Missing []string
+}
+
+// Error returns the error message with the unknown and missing columns
+func (e *CSVHeaderError) Error() string {
+	var problems []string
+
+	if len(e.Unknown) > 0 {
+		unknown := make([]string, 0, len(e.Unknown))
+
+		for _, c := range e.Unknown {
+			if c.Suggestion != "" {
+				unknown = append(unknown, fmt.Sprintf("%s (did you mean %s?)", c.Header, c.Suggestion))
+			} else {
+				unknown = append(unknown, c.Header)
+			}
+		}
+
+		problems = append(problems, "unknown columns: "+strings.Join(unknown, ", "))
+	}
+
+	if len(e.Missing) > 0 {
+		problems = append(problems, "missing required columns: "+strings.Join(e.Missing, ", "))
+	}
+
+	return fmt.Sprintf("%v for %s: %s", ErrInvalidCSVHeader, e.Object, strings.Join(problems, "; "))
+}
+
+// Unwrap returns ErrInvalidCSVHeader so the error can be checked with errors.Is
+func (e *CSVHeaderError) Unwrap() error {
+	return ErrInvalidCSVHeader
+}
+
+// Extensions returns the graphql error extensions with the unknown and missing columns
+func (e *CSVHeaderError) Extensions() map[string]any {
+	return map[string]any{
+		"code":           invalidCSVHeaderCode,
+		"object":         e.Object,
+		"unknownColumns": e.Unknown,
+		"missingColumns": e.Missing,
+	}
+}
+
+// ValidateCSVHeader checks the header of a CSV upload against the known headers of the object, unknown columns
+// are returned with the closest known header as a suggestion. Required columns are keyed by header with the
+// custom columns that can be set instead, e.g. the email of a user instead of its id. An empty header is
+// not checked so the upload is reported as empty instead
+func ValidateCSVHeader(object string, header, known []string, required map[string][]string) error {
+	if len(header) == 0 {
+		return nil
+	}
+
+	present := make(map[string]bool, len(header))
+	headerErr := &CSVHeaderError{Object: object}
+
+	for _, h := range header {
+		if h == "" {
+			continue
+		}
+
+		present[h] = true
+
+		if !slices.Contains(known, h) {
+			headerErr.Unknown = append(headerErr.Unknown, UnknownCSVColumn{Header: h, Suggestion: closestCSVHeader(h, known)})
+		}
+	}
+
+	for column, alternatives := range required {
+		if present[column] || slices.ContainsFunc(alternatives, func(a string) bool { return present[a] }) {
+			continue
+		}
+
+		headerErr.Missing = append(headerErr.Missing, column)
+	}
+
+	if len(headerErr.Unknown) == 0 && len(headerErr.Missing) == 0 {
+		return nil
+	}
+
+	slices.Sort(headerErr.Missing)
+
+	return headerErr
+}
+
+const (
+	// minSuggestionDistance is the edit distance always accepted for a suggested header
+	minSuggestionDistance = 2
+	// suggestionDistanceDivisor allows longer headers an edit distance of a third of their length
+	suggestionDistanceDivisor = 3
+)
+
+// closestCSVHeader returns the known header with the smallest edit distance to the header, ignoring case,
+// or an empty string when no known header is close enough to be a likely misspelling
+func closestCSVHeader(header string, known []string) string {
+	lower := strings.ToLower(header)
+	maxDistance := max(minSuggestionDistance, len(lower)/suggestionDistanceDivisor)
+
+	closest := ""
+	closestDistance := maxDistance + 1
+
+	for _, k := range known {
+		if d := editDistance(lower, strings.ToLower(k)); d < closestDistance {
+			closest = k
+			closestDistance = d
+		}
+	}
+
+	return closest
+}
+
+// editDistance returns the levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
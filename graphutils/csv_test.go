@@ -0,0 +1,260 @@
+package graphutils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// controlStatus is a string enum like the ent enums
+type controlStatus string
+
+func TestFormatCSVValue(t *testing.T) {
+	name := "Access Control"
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var nilName *string
+
+	testCases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "nil", value: nil, expected: ""},
+		{name: "nil pointer", value: nilName, expected: ""},
+		{name: "string", value: "AC-1", expected: "AC-1"},
+		{name: "string pointer", value: &name, expected: "Access Control"},
+		{name: "enum", value: controlStatus("APPROVED"), expected: "APPROVED"},
+		{name: "bool", value: true, expected: "true"},
+		{name: "int", value: 42, expected: "42"},
+		{name: "time", value: createdAt, expected: "2025-01-02T03:04:05Z"},
+		{name: "zero time", value: time.Time{}, expected: ""},
+		{name: "slice", value: []string{"soc2", "iso27001"}, expected: `["soc2","iso27001"]`},
+		{name: "empty slice", value: []string{}, expected: "[]"},
+		{name: "nil slice", value: []string(nil), expected: ""},
+		{name: "map", value: map[string]any{"key": "value"}, expected: `{"key":"value"}`},
+		{name: "struct", value: struct {
+			Name string `json:"name"`
+		}{Name: "AC"}, expected: `{"name":"AC"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FormatCSVValue(tc.value))
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf strings.Builder
+
+	err := WriteCSV(&buf, []string{"ID", "RefCode", "Tags"}, []map[string]string{
+		{"ID": "01HX", "RefCode": "AC-1", "Tags": `["soc2","iso27001"]`},
+		{"ID": "01HY"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ID,RefCode,Tags\n01HX,AC-1,\"[\"\"soc2\"\",\"\"iso27001\"\"]\"\n01HY,,\n", buf.String())
+}
+
+func TestStreamCSVExport(t *testing.T) {
+	errQuery := errors.New("query failed")
+	errStorage := errors.New("storage unavailable")
+
+	pages := func(w *CSVExportWriter) error {
+		if err := w.WritePage([]map[string]string{{"ID": "01HX", "RefCode": "AC-1"}}); err != nil {
+			return err
+		}
+
+		return w.WritePage([]map[string]string{{"ID": "01HY"}})
+	}
+
+	readAll := func(_ context.Context, schema string, file io.Reader) (string, error) {
+		b, err := io.ReadAll(file)
+		if err != nil {
+			return "", err
+		}
+
+		return schema + ":" + string(b), nil
+	}
+
+	testCases := []struct {
+		name        string
+		write       func(*CSVExportWriter) error
+		upload      CSVExportUploader
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "pages are uploaded",
+			write:    pages,
+			upload:   readAll,
+			expected: "Control:ID,RefCode\n01HX,AC-1\n01HY,\n",
+		},
+		{
+			name: "write error",
+			write: func(*CSVExportWriter) error {
+				return errQuery
+			},
+			upload:      readAll,
+			expectedErr: errQuery,
+		},
+		{
+			name:  "upload error",
+			write: pages,
+			upload: func(context.Context, string, io.Reader) (string, error) {
+				return "", errStorage
+			},
+			expectedErr: errStorage,
+		},
+		{
+			name:  "upload does not read the export",
+			write: pages,
+			upload: func(context.Context, string, io.Reader) (string, error) {
+				return "ref", nil
+			},
+			expectedErr: io.ErrClosedPipe,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := StreamCSVExport(context.Background(), "Control", []string{"ID", "RefCode"}, tc.write, tc.upload)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Empty(t, ref)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ref)
+		})
+	}
+}
+
+func TestReadCSVHeader(t *testing.T) {
+	file := strings.NewReader("RefCode,Title\nAC-1,Access Control\n")
+
+	header, err := ReadCSVHeader(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RefCode", "Title"}, header)
+
+	// the file is rewound so the rows can still be unmarshalled
+	offset, err := file.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Zero(t, offset)
+
+	header, err = ReadCSVHeader(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, header)
+}
+
+func TestTrimCSVHeaderMarkers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		file     string
+		expected string
+	}{
+		{
+			name:     "required markers",
+			file:     "ID*,RefCode*,Title\n01HX,AC-1,\"Access, Control\"\n",
+			expected: "ID,RefCode,Title\n01HX,AC-1,\"Access, Control\"\n",
+		},
+		{
+			name:     "no markers",
+			file:     "RefCode,Title\nAC-1,Access Control\n",
+			expected: "RefCode,Title\nAC-1,Access Control\n",
+		},
+		{
+			name: "empty file",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := TrimCSVHeaderMarkers(strings.NewReader(tc.file))
+			require.NoError(t, err)
+
+			b, err := io.ReadAll(file)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, string(b))
+		})
+	}
+}
+
+func TestValidateCSVHeader(t *testing.T) {
+	known := []string{"RefCode", "Title", "Status", "OwnerId", "ControlOwnerEmail"}
+	required := map[string][]string{"RefCode": nil, "OwnerId": {"ControlOwnerEmail"}}
+
+	testCases := []struct {
+		name            string
+		header          []string
+		expectedUnknown []UnknownCSVColumn
+		expectedMissing []string
+	}{
+		{
+			name:   "valid",
+			header: []string{"RefCode", "Title", "OwnerId"},
+		},
+		{
+			name:   "required column set with a custom column",
+			header: []string{"RefCode", "ControlOwnerEmail"},
+		},
+		{
+			name:   "empty header",
+			header: nil,
+		},
+		{
+			name:   "misspelled and unknown columns",
+			header: []string{"RefCode", "Titel", "status", "Framework", "OwnerId", ""},
+			expectedUnknown: []UnknownCSVColumn{
+				{Header: "Titel", Suggestion: "Title"},
+				{Header: "status", Suggestion: "Status"},
+				{Header: "Framework"},
+			},
+		},
+		{
+			name:            "missing required columns",
+			header:          []string{"Title"},
+			expectedMissing: []string{"OwnerId", "RefCode"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCSVHeader("control", tc.header, known, required)
+			if tc.expectedUnknown == nil && tc.expectedMissing == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidCSVHeader)
+
+			var headerErr *CSVHeaderError
+			require.ErrorAs(t, err, &headerErr)
+
+			assert.Equal(t, tc.expectedUnknown, headerErr.Unknown)
+			assert.Equal(t, tc.expectedMissing, headerErr.Missing)
+			assert.Equal(t, "INVALID_CSV_HEADER", headerErr.Extensions()["code"])
+		})
+	}
+}
+
+func TestCSVHeaderErrorMessage(t *testing.T) {
+	err := &CSVHeaderError{
+		Object:  "control",
+		Unknown: []UnknownCSVColumn{{Header: "Titel", Suggestion: "Title"}, {Header: "Framework"}},
+		Missing: []string{"RefCode"},
+	}
+
+	assert.Equal(t, "invalid csv header for control: unknown columns: Titel (did you mean Title?), Framework; missing required columns: RefCode", err.Error())
+}
@@ -0,0 +1,383 @@
+package graphutils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrBulkJobNotFound is returned when the bulk job does not exist
+	ErrBulkJobNotFound = errors.New("bulk job not found")
+	// ErrInvalidBulkJobState is returned when a bulk job state is not one of the known states
+	ErrInvalidBulkJobState = errors.New("invalid bulk job state")
+	// ErrBulkJobPanicked is the error of a bulk job that panicked while it was running
+	ErrBulkJobPanicked = errors.New("bulk job panicked")
+)
+
+// bulkJobIDBytes is the number of random bytes of a bulk job id
+const bulkJobIDBytes = 16
+
+const (
+	// DefaultBulkJobRetention is how long the MemoryBulkJobRunner keeps the status of a finished job
+	DefaultBulkJobRetention = time.Hour
+	// DefaultMaxFinishedBulkJobs is the number of finished jobs the MemoryBulkJobRunner keeps the status of
+	DefaultMaxFinishedBulkJobs = 1000
+)
+
+// BulkJobState is the state of a bulk job
+type BulkJobState string
+
+const (
+	// BulkJobStatePending is the state of a job that was enqueued and has not started
+	BulkJobStatePending BulkJobState = "PENDING"
+	// BulkJobStateRunning is the state of a job that is processing its rows
+	BulkJobStateRunning BulkJobState = "RUNNING"
+	// BulkJobStateCompleted is the state of a job that processed all rows, rows can still have failed
+	BulkJobStateCompleted BulkJobState = "COMPLETED"
+	// BulkJobStateFailed is the state of a job that stopped before all rows were processed
+	BulkJobStateFailed BulkJobState = "FAILED"
+)
+
+// MarshalGQL implements the graphql.Marshaler interface so the type can be bound to a graphql enum
+func (s BulkJobState) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(string(s)))
+}
+
+// UnmarshalGQL implements the graphql.Unmarshaler interface so the type can be bound to a graphql enum
+func (s *BulkJobState) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%w: %v", ErrInvalidBulkJobState, v)
+	}
+
+	switch state := BulkJobState(str); state {
+	case BulkJobStatePending, BulkJobStateRunning, BulkJobStateCompleted, BulkJobStateFailed:
+		*s = state
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidBulkJobState, str)
+	}
+}
+
+// BulkJobStatus is the progress of a bulk job, it is returned by the job variants of the CSV bulk
+// mutations when the job is enqueued and by the bulkJob query while the job runs
+type BulkJobStatus struct {
+	// ID is the id of the job
+	ID string `json:"id"`
+	// Object is the name of the object of the job, e.g. control
+	Object string `json:"object"`
+	// Operation is the bulk operation of the job, e.g. create or update
+	Operation string `json:"operation"`
+	// State is the state of the job
+	State BulkJobState `json:"state"`
+	// Total is the number of rows of the job
+	Total int `json:"total"`
+	// Processed is the number of rows processed so far, including the failed rows
+	Processed int `json:"processed"`
+	// Failed is the number of rows that failed
+	Failed int `json:"failed"`
+	// Errors are the results of the failed rows with their index, code and message
+	Errors []*BulkItemResult `json:"errors,omitempty"`
+	// Error is the error that stopped a failed job
+	Error string `json:"error,omitempty"`
+}
+
+// BulkJobProgress records the result of each row of a running bulk job
+type BulkJobProgress interface {
+	// Succeeded records a row that was saved, with the id of the saved object
+	Succeeded(index int, id string)
+	// Failed records a row that was not saved, the id is empty for rows that failed before they were created
+	Failed(index int, id string, err error)
+}
+
+// BulkJob is the work of a bulk job enqueued by the generated bulk resolvers
+type BulkJob struct {
+	// Object is the name of the object of the job, e.g. control
+	Object string
+	// Operation is the bulk operation of the job, e.g. create or update
+	Operation string
+	// Total is the number of rows of the job
+	Total int
+	// Run processes the rows and records the result of each row, an error stops the job
+	Run func(ctx context.Context, progress BulkJobProgress) error
+}
+
+// BulkJobRunner runs the bulk jobs of the job variants of the CSV bulk mutations in the background and
+// reports their progress to the bulkJob query. The job is run after the request returns, so Run must be
+// called with a context that is not canceled with the request, e.g. from context.WithoutCancel. Runners
+// are responsible for only returning the status of a job to the users allowed to see it
+type BulkJobRunner interface {
+	// Enqueue starts the job and returns its pending status with the id of the job
+	Enqueue(ctx context.Context, job BulkJob) (*BulkJobStatus, error)
+	// Status returns the current status of the job, or ErrBulkJobNotFound
+	Status(ctx context.Context, id string) (*BulkJobStatus, error)
+}
+
+// BulkJobOwner is the user and organization that enqueued a bulk job
+type BulkJobOwner struct {
+	// UserID is the id of the user that enqueued the job
+	UserID string
+	// OrganizationID is the id of the organization the job was enqueued in
+	OrganizationID string
+}
+
+// BulkJobOwnerFunc returns the user and organization of the request, e.g. from the authenticated user in the context
+type BulkJobOwnerFunc func(ctx context.Context) (BulkJobOwner, error)
+
+// MemoryBulkJobRunner is a BulkJobRunner that runs each job in a goroutine and keeps the status of
+// the jobs in memory, it is intended for tests and single instance deployments since the jobs are
+// lost on restart and the status is not shared between instances. The status of a job is only
+// returned to the user that enqueued it in the same organization, and finished jobs are evicted
+// after the retention or when there are more than the max finished jobs
+type MemoryBulkJobRunner struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryBulkJob
+	// finished are the ids of the finished jobs, in the order they finished
+	finished []string
+
+	owner       BulkJobOwnerFunc
+	retention   time.Duration
+	maxFinished int
+	now         func() time.Time
+}
+
+// memoryBulkJob is a job of the MemoryBulkJobRunner, done is closed when the job finishes
+type memoryBulkJob struct {
+	status     BulkJobStatus
+	owner      BulkJobOwner
+	finishedAt time.Time
+	done       chan struct{}
+}
+
+// MemoryBulkJobRunnerOption configures a MemoryBulkJobRunner
+type MemoryBulkJobRunnerOption func(*MemoryBulkJobRunner)
+
+// WithBulkJobRetention sets how long the status of a finished job is kept, zero or negative values keep
+// the DefaultBulkJobRetention
+func WithBulkJobRetention(retention time.Duration) MemoryBulkJobRunnerOption {
+	return func(m *MemoryBulkJobRunner) {
+		if retention > 0 {
+			m.retention = retention
+		}
+	}
+}
+
+// WithMaxFinishedBulkJobs sets the number of finished jobs the status is kept of, the oldest finished jobs
+// are evicted first. Negative values keep the DefaultMaxFinishedBulkJobs
+func WithMaxFinishedBulkJobs(maxFinished int) MemoryBulkJobRunnerOption {
+	return func(m *MemoryBulkJobRunner) {
+		if maxFinished >= 0 {
+			m.maxFinished = maxFinished
+		}
+	}
+}
+
+// NewMemoryBulkJobRunner returns an empty MemoryBulkJobRunner, owner returns the user and organization of
+// the request that are recorded with each job and checked when its status is requested
+func NewMemoryBulkJobRunner(owner BulkJobOwnerFunc, opts ...MemoryBulkJobRunnerOption) *MemoryBulkJobRunner {
+	m := &MemoryBulkJobRunner{
+		jobs:        map[string]*memoryBulkJob{},
+		owner:       owner,
+		retention:   DefaultBulkJobRetention,
+		maxFinished: DefaultMaxFinishedBulkJobs,
+		now:         time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Enqueue starts the job in a new goroutine with a context that is not canceled with the request
+func (m *MemoryBulkJobRunner) Enqueue(ctx context.Context, job BulkJob) (*BulkJobStatus, error) {
+	owner, err := m.owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newBulkJobID()
+	if err != nil {
+		return nil, err
+	}
+
+	j := &memoryBulkJob{
+		status: BulkJobStatus{
+			ID:        id,
+			Object:    job.Object,
+			Operation: job.Operation,
+			State:     BulkJobStatePending,
+			Total:     job.Total,
+		},
+		owner: owner,
+		done:  make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.evictLocked()
+	m.jobs[id] = j
+	status := j.status.clone()
+	m.mu.Unlock()
+
+	go m.run(context.WithoutCancel(ctx), j, job)
+
+	return status, nil
+}
+
+// Status returns a copy of the current status of the job, a job enqueued by another user or in another
+// organization is not found
+func (m *MemoryBulkJobRunner) Status(ctx context.Context, id string) (*BulkJobStatus, error) {
+	j, err := m.job(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return j.status.clone(), nil
+}
+
+// Wait blocks until the job is completed or failed and returns its final status, or returns the
+// error of the context when it is done first
+func (m *MemoryBulkJobRunner) Wait(ctx context.Context, id string) (*BulkJobStatus, error) {
+	j, err := m.job(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-j.done:
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		return j.status.clone(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// job returns the job when it was enqueued by the user and organization of the request
+func (m *MemoryBulkJobRunner) job(ctx context.Context, id string) (*memoryBulkJob, error) {
+	owner, err := m.owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evictLocked()
+
+	j, ok := m.jobs[id]
+	if !ok || j.owner != owner {
+		return nil, fmt.Errorf("%w: %s", ErrBulkJobNotFound, id)
+	}
+
+	return j, nil
+}
+
+// evictLocked removes the finished jobs that are older than the retention and the oldest finished jobs
+// over the max, the lock of the runner must be held
+func (m *MemoryBulkJobRunner) evictLocked() {
+	expired := m.now().Add(-m.retention)
+
+	evict := 0
+	for evict < len(m.finished) && (len(m.finished)-evict > m.maxFinished || !m.jobs[m.finished[evict]].finishedAt.After(expired)) {
+		delete(m.jobs, m.finished[evict])
+		evict++
+	}
+
+	m.finished = slices.Delete(m.finished, 0, evict)
+}
+
+// run runs the job and records its final state, a panic fails the job instead of the process
+func (m *MemoryBulkJobRunner) run(ctx context.Context, j *memoryBulkJob, job BulkJob) {
+	defer close(j.done)
+
+	m.update(j, func(s *BulkJobStatus) {
+		s.State = BulkJobStateRunning
+	})
+
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("%w: %v", ErrBulkJobPanicked, p)
+			}
+		}()
+
+		return job.Run(ctx, &memoryBulkJobProgress{runner: m, job: j})
+	}()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j.status.State = BulkJobStateCompleted
+
+	if err != nil {
+		j.status.State = BulkJobStateFailed
+		j.status.Error = err.Error()
+	}
+
+	j.finishedAt = m.now()
+	m.finished = append(m.finished, j.status.ID)
+
+	m.evictLocked()
+}
+
+// update changes the status of the job while holding the lock of the runner
+func (m *MemoryBulkJobRunner) update(j *memoryBulkJob, fn func(s *BulkJobStatus)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fn(&j.status)
+}
+
+// memoryBulkJobProgress records the row results of a job of the MemoryBulkJobRunner
+type memoryBulkJobProgress struct {
+	runner *MemoryBulkJobRunner
+	job    *memoryBulkJob
+}
+
+// Succeeded counts the row as processed
+func (p *memoryBulkJobProgress) Succeeded(_ int, _ string) {
+	p.runner.update(p.job, func(s *BulkJobStatus) {
+		s.Processed++
+	})
+}
+
+// Failed counts the row as processed and failed and records its error
+func (p *memoryBulkJobProgress) Failed(index int, id string, err error) {
+	p.runner.update(p.job, func(s *BulkJobStatus) {
+		s.Processed++
+		s.Failed++
+		s.Errors = append(s.Errors, NewBulkItemFailure(index, id, err))
+	})
+}
+
+// clone returns a copy of the status that can be returned while the job keeps running
+func (s BulkJobStatus) clone() *BulkJobStatus {
+	s.Errors = slices.Clone(s.Errors)
+
+	return &s
+}
+
+// newBulkJobID returns a random id for a bulk job
+func newBulkJobID() (string, error) {
+	b := make([]byte, bulkJobIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,265 @@
+package graphutils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkJobOwnerKey is the context key of the owner returned by testBulkJobOwner
+type bulkJobOwnerKey struct{}
+
+// errNoBulkJobOwner is returned by testBulkJobOwner for a context without an owner
+var errNoBulkJobOwner = errors.New("no authenticated user") //nolint:err113
+
+// withBulkJobOwner returns a context with the owner returned by testBulkJobOwner
+func withBulkJobOwner(ctx context.Context, userID, orgID string) context.Context {
+	return context.WithValue(ctx, bulkJobOwnerKey{}, BulkJobOwner{UserID: userID, OrganizationID: orgID})
+}
+
+// testBulkJobOwner returns the owner set in the context with withBulkJobOwner
+func testBulkJobOwner(ctx context.Context) (BulkJobOwner, error) {
+	owner, ok := ctx.Value(bulkJobOwnerKey{}).(BulkJobOwner)
+	if !ok {
+		return BulkJobOwner{}, errNoBulkJobOwner
+	}
+
+	return owner, nil
+}
+
+func TestMemoryBulkJobRunner(t *testing.T) {
+	errRow := errors.New("row failed")   //nolint:err113
+	errStop := errors.New("job stopped") //nolint:err113
+
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context, progress BulkJobProgress) error
+		state     BulkJobState
+		processed int
+		failed    int
+		errors    []*BulkItemResult
+		err       string
+	}{
+		{
+			name: "all rows succeed",
+			run: func(_ context.Context, progress BulkJobProgress) error {
+				progress.Succeeded(0, "01HX")
+				progress.Succeeded(1, "01HY")
+				progress.Succeeded(2, "01HZ")
+
+				return nil
+			},
+			state:     BulkJobStateCompleted,
+			processed: 3,
+		},
+		{
+			name: "some rows fail",
+			run: func(_ context.Context, progress BulkJobProgress) error {
+				progress.Succeeded(0, "01HX")
+				progress.Failed(1, "", errRow)
+				progress.Failed(2, "01HZ", errRow)
+
+				return nil
+			},
+			state:     BulkJobStateCompleted,
+			processed: 3,
+			failed:    2,
+			errors: []*BulkItemResult{
+				{Index: 1, Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "row failed"},
+				{Index: 2, ID: "01HZ", Status: BulkItemStatusFailed, Code: "BULK_ITEM_FAILED", Message: "row failed"},
+			},
+		},
+		{
+			name: "job returns an error",
+			run: func(_ context.Context, progress BulkJobProgress) error {
+				progress.Succeeded(0, "01HX")
+
+				return errStop
+			},
+			state:     BulkJobStateFailed,
+			processed: 1,
+			err:       "job stopped",
+		},
+		{
+			name: "job panics",
+			run: func(_ context.Context, _ BulkJobProgress) error {
+				panic("boom")
+			},
+			state: BulkJobStateFailed,
+			err:   "bulk job panicked: boom",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := NewMemoryBulkJobRunner(testBulkJobOwner)
+			owner := withBulkJobOwner(context.Background(), "user", "org")
+
+			// the job keeps running after the request context is canceled
+			ctx, cancel := context.WithCancel(owner)
+
+			status, err := runner.Enqueue(ctx, BulkJob{Object: "control", Operation: "create", Total: 3, Run: tc.run})
+			require.NoError(t, err)
+			cancel()
+
+			require.NotEmpty(t, status.ID)
+			assert.Equal(t, "control", status.Object)
+			assert.Equal(t, "create", status.Operation)
+			assert.Equal(t, BulkJobStatePending, status.State)
+			assert.Equal(t, 3, status.Total)
+
+			waitCtx, waitCancel := context.WithTimeout(owner, 5*time.Second)
+			defer waitCancel()
+
+			final, err := runner.Wait(waitCtx, status.ID)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.state, final.State)
+			assert.Equal(t, tc.processed, final.Processed)
+			assert.Equal(t, tc.failed, final.Failed)
+			assert.Equal(t, tc.errors, final.Errors)
+			assert.Equal(t, tc.err, final.Error)
+
+			current, err := runner.Status(owner, status.ID)
+			require.NoError(t, err)
+			assert.Equal(t, final, current)
+		})
+	}
+}
+
+func TestMemoryBulkJobRunnerNotFound(t *testing.T) {
+	runner := NewMemoryBulkJobRunner(testBulkJobOwner)
+	ctx := withBulkJobOwner(context.Background(), "user", "org")
+
+	_, err := runner.Status(ctx, "missing")
+	require.ErrorIs(t, err, ErrBulkJobNotFound)
+
+	_, err = runner.Wait(ctx, "missing")
+	require.ErrorIs(t, err, ErrBulkJobNotFound)
+}
+
+func TestMemoryBulkJobRunnerOwner(t *testing.T) {
+	runner := NewMemoryBulkJobRunner(testBulkJobOwner)
+	ctx := withBulkJobOwner(context.Background(), "user", "org")
+
+	_, err := runner.Enqueue(context.Background(), BulkJob{Object: "control", Operation: "create", Run: func(context.Context, BulkJobProgress) error { return nil }})
+	require.ErrorIs(t, err, errNoBulkJobOwner)
+
+	status, err := runner.Enqueue(ctx, BulkJob{Object: "control", Operation: "create", Run: func(context.Context, BulkJobProgress) error { return nil }})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		expectedErr error
+	}{
+		{
+			name: "same user and organization",
+			ctx:  ctx,
+		},
+		{
+			name:        "other user",
+			ctx:         withBulkJobOwner(context.Background(), "other", "org"),
+			expectedErr: ErrBulkJobNotFound,
+		},
+		{
+			name:        "other organization",
+			ctx:         withBulkJobOwner(context.Background(), "user", "other"),
+			expectedErr: ErrBulkJobNotFound,
+		},
+		{
+			name:        "no owner",
+			ctx:         context.Background(),
+			expectedErr: errNoBulkJobOwner,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			current, err := runner.Status(tc.ctx, status.ID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, current)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, status.ID, current.ID)
+		})
+	}
+}
+
+func TestMemoryBulkJobRunnerEviction(t *testing.T) {
+	ctx := withBulkJobOwner(context.Background(), "user", "org")
+	done := func(context.Context, BulkJobProgress) error { return nil }
+
+	// enqueue runs the job to the end and returns its id
+	enqueue := func(t *testing.T, runner *MemoryBulkJobRunner) string {
+		t.Helper()
+
+		status, err := runner.Enqueue(ctx, BulkJob{Object: "control", Operation: "create", Run: done})
+		require.NoError(t, err)
+
+		_, err = runner.Wait(ctx, status.ID)
+		require.NoError(t, err)
+
+		return status.ID
+	}
+
+	t.Run("retention", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		runner := NewMemoryBulkJobRunner(testBulkJobOwner, WithBulkJobRetention(time.Minute))
+		runner.now = func() time.Time { return now }
+
+		id := enqueue(t, runner)
+
+		now = now.Add(time.Minute - time.Second)
+
+		_, err := runner.Status(ctx, id)
+		require.NoError(t, err)
+
+		now = now.Add(time.Second)
+
+		_, err = runner.Status(ctx, id)
+		require.ErrorIs(t, err, ErrBulkJobNotFound)
+		assert.Empty(t, runner.jobs)
+	})
+
+	t.Run("max finished jobs", func(t *testing.T) {
+		runner := NewMemoryBulkJobRunner(testBulkJobOwner, WithMaxFinishedBulkJobs(2))
+
+		first := enqueue(t, runner)
+		second := enqueue(t, runner)
+		third := enqueue(t, runner)
+
+		_, err := runner.Status(ctx, first)
+		require.ErrorIs(t, err, ErrBulkJobNotFound)
+
+		for _, id := range []string{second, third} {
+			_, err := runner.Status(ctx, id)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestBulkJobStateGQL(t *testing.T) {
+	var buf bytes.Buffer
+
+	BulkJobStateRunning.MarshalGQL(&buf)
+	assert.Equal(t, `"RUNNING"`, buf.String())
+
+	var state BulkJobState
+
+	require.NoError(t, state.UnmarshalGQL("COMPLETED"))
+	assert.Equal(t, BulkJobStateCompleted, state)
+
+	require.ErrorIs(t, state.UnmarshalGQL("DONE"), ErrInvalidBulkJobState)
+	require.ErrorIs(t, state.UnmarshalGQL(1), ErrInvalidBulkJobState)
+}
@@ -0,0 +1,35 @@
+package graphutils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAmbiguousUpsertKey is returned by the generated bulk upsert resolvers when more than one existing
+// object matches the natural key of a row, so it is not known which object should be updated
+var ErrAmbiguousUpsertKey = errors.New("more than one object matches the upsert key")
+
+// UpsertRow is a row of a bulk upsert, the row updates the object with the ID when it is set, otherwise the
+// object matching the natural key of the input when one is configured, or else creates a new object
+type UpsertRow[T any] struct {
+	// ID is the id of the object to update, empty for new objects or objects matched by the natural key
+	ID string
+	// Input is the create input of the row, the values are set on the matched object when it exists
+	Input *T
+}
+
+// NewUpsertRows returns the upsert rows of the inputs, the rows do not have an id so they are only
+// matched by the natural key
+func NewUpsertRows[T any](inputs []*T) []*UpsertRow[T] {
+	rows := make([]*UpsertRow[T], 0, len(inputs))
+	for _, input := range inputs {
+		rows = append(rows, &UpsertRow[T]{Input: input})
+	}
+
+	return rows
+}
+
+// NewAmbiguousUpsertKeyError returns an ErrAmbiguousUpsertKey error with the key field and value
+func NewAmbiguousUpsertKeyError(key string, value any) error {
+	return fmt.Errorf("%w: %s %v", ErrAmbiguousUpsertKey, key, value)
+}
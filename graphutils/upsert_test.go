@@ -0,0 +1,33 @@
+package graphutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpsertRows(t *testing.T) {
+	type createInput struct {
+		RefCode string
+	}
+
+	inputs := []*createInput{{RefCode: "CTL-1"}, {RefCode: "CTL-2"}}
+
+	rows := NewUpsertRows(inputs)
+	require.Len(t, rows, 2)
+
+	for i, row := range rows {
+		assert.Empty(t, row.ID)
+		assert.Same(t, inputs[i], row.Input)
+	}
+
+	assert.Empty(t, NewUpsertRows[createInput](nil))
+}
+
+func TestNewAmbiguousUpsertKeyError(t *testing.T) {
+	err := NewAmbiguousUpsertKeyError("refCode", "CTL-1")
+
+	require.ErrorIs(t, err, ErrAmbiguousUpsertKey)
+	assert.Equal(t, "more than one object matches the upsert key: refCode CTL-1", err.Error())
+}
@@ -0,0 +1,152 @@
+package resolvergen
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestArchiveOperations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		field    string
+		expected string
+	}{
+		{name: "archive", field: "ArchiveControl", expected: ArchiveOperation},
+		{name: "archive bulk", field: "ArchiveBulkControl", expected: ArchiveOperation},
+		{name: "unarchive", field: "UnarchiveControl", expected: UnarchiveOperation},
+		{name: "unarchive bulk", field: "UnarchiveBulkControl", expected: UnarchiveOperation},
+		{name: "archive in entity name", field: "CreateArchiveRecord", expected: CreateOperation},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().crudType(newCRUDField(tc.field, "ControlArchivePayload")))
+		})
+	}
+
+	assert.Equal(t, "Control", getEntityName("ControlUnarchivePayload"))
+	assert.Equal(t, "Control", getEntityName("ControlBulkArchivePayload"))
+}
+
+func TestRenderArchive(t *testing.T) {
+	stubReserveImport(t)
+
+	idArgs := ast.ArgumentDefinitionList{{Name: "id", Type: ast.NonNullNamedType("ID", nil)}}
+	idsArgs := ast.ArgumentDefinitionList{{Name: "ids", Type: ast.NonNullListType(ast.NonNullNamedType("ID", nil), nil)}}
+	statusArg := &ast.ArgumentDefinition{Name: "status", Type: ast.NamedType("ControlStatus", nil)}
+
+	testCases := []struct {
+		name        string
+		field       string
+		returnType  string
+		args        ast.ArgumentDefinitionList
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:       "archive",
+			field:      "ArchiveControl",
+			returnType: "ControlArchivePayload",
+			args:       idArgs,
+			contains: []string{
+				"current, err := c.Control.Get(ctx, id)",
+				"if current.Status == enums.ControlStatusArchived {",
+				"Where(control.StatusEQ(current.Status)).",
+				"SetPreviousStatus(current.Status).",
+				"SetStatus(enums.ControlStatusArchived).",
+				"ControlArchivePayload{",
+			},
+		},
+		{
+			name:       "archive bulk",
+			field:      "ArchiveBulkControl",
+			returnType: "ControlBulkArchivePayload",
+			args:       idsArgs,
+			contains: []string{
+				`rout.NewMissingRequiredFieldError("ids")`,
+				"Where(control.IDIn(ids...), control.StatusNEQ(enums.ControlStatusArchived)).",
+				"SetPreviousStatus(record.Status).",
+				"SetStatus(enums.ControlStatusArchived).",
+				"if generated.IsNotFound(err) {",
+				"ArchivedIDs: archivedIDs,",
+			},
+			notContains: []string{"ArchivedIDs: ids,"},
+		},
+		{
+			name:       "unarchive",
+			field:      "UnarchiveControl",
+			returnType: "ControlUnarchivePayload",
+			args:       idArgs,
+			contains: []string{
+				"if current.Status != enums.ControlStatusArchived {",
+				`graphutils.NewNotArchivedError("control", id)`,
+				"restoredStatus := control.DefaultStatus",
+				"restoredStatus = *current.PreviousStatus",
+				"Where(control.StatusEQ(enums.ControlStatusArchived)).",
+				"SetStatus(restoredStatus).",
+				"ClearPreviousStatus().",
+				"ControlUnarchivePayload{",
+			},
+			notContains: []string{"*status"},
+		},
+		{
+			name:       "unarchive with status",
+			field:      "UnarchiveControl",
+			returnType: "ControlUnarchivePayload",
+			args:       append(idArgs, statusArg),
+			contains: []string{
+				"restoredStatus = *current.PreviousStatus",
+				"restoredStatus = *status",
+			},
+		},
+		{
+			name:       "unarchive bulk",
+			field:      "UnarchiveBulkControl",
+			returnType: "ControlBulkUnarchivePayload",
+			args:       append(idsArgs, statusArg),
+			contains: []string{
+				"Where(control.IDIn(ids...), control.StatusEQ(enums.ControlStatusArchived)).",
+				"Select(control.FieldID, control.FieldPreviousStatus).",
+				"restoredStatus = *record.PreviousStatus",
+				"restoredStatus = *status",
+				"ClearPreviousStatus().",
+				"UnarchivedIDs: unarchivedIDs,",
+			},
+			notContains: []string{"UnarchivedIDs: ids,"},
+		},
+	}
+
+	plugin := NewWithOptions(
+		WithEntGeneratedPackage("github.com/example/ent/generated"),
+		WithArchivableSchemas([]string{"Control"}),
+	)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := newCRUDField(tc.field, tc.returnType)
+			field.FieldDefinition.Arguments = tc.args
+
+			rendered, err := plugin.mutationImplementer(field)
+			require.NoError(t, err)
+
+			for _, c := range tc.contains {
+				assert.Contains(t, rendered, c)
+			}
+
+			for _, c := range tc.notContains {
+				assert.NotContains(t, rendered, c)
+			}
+		})
+	}
+
+	t.Run("schema that is not archivable is not implemented", func(t *testing.T) {
+		rendered, err := plugin.mutationImplementer(newCRUDField("ArchiveTask", "TaskArchivePayload"))
+		require.NoError(t, err)
+
+		assert.Equal(t, fmt.Sprintf(defaultImplementation, "ArchiveTask", "ArchiveTask"), rendered)
+	})
+}
@@ -0,0 +1,104 @@
+package resolvergen
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// newUpdateField returns an update field with an input argument with the given input fields
+func newUpdateField(goFieldName, returnType string, inputFields ...string) *codegen.Field {
+	field := newCRUDField(goFieldName, returnType)
+
+	input := &ast.Definition{Name: goFieldName + "Input", Kind: ast.InputObject}
+	for _, name := range inputFields {
+		input.Fields = append(input.Fields, &ast.FieldDefinition{Name: name})
+	}
+
+	field.Args = []*codegen.FieldArgument{
+		{
+			ArgumentDefinition: &ast.ArgumentDefinition{Name: "input"},
+			TypeReference:      &config.TypeReference{Definition: input},
+		},
+	}
+
+	return field
+}
+
+func TestRenderUpdateOptimisticConcurrency(t *testing.T) {
+	stubReserveImport(t)
+
+	testCases := []struct {
+		name        string
+		opts        []Options
+		inputFields []string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "disabled by default",
+			inputFields: []string{"name", "expectedUpdatedAt"},
+			notContains: []string{"graphutils.Check", "req.Where"},
+		},
+		{
+			name:        "expected updated at",
+			opts:        []Options{WithOptimisticConcurrency()},
+			inputFields: []string{"name", "expectedUpdatedAt"},
+			contains: []string{
+				"req.Where(control.UpdatedAtEQ(*input.ExpectedUpdatedAt))",
+				"generated.IsNotFound(err) && input.ExpectedUpdatedAt != nil",
+				`graphutils.CheckUpdatedAt("control", id, input.ExpectedUpdatedAt, current.UpdatedAt)`,
+			},
+			notContains: []string{"CheckVersion", "AddVersion", "res.UpdatedAt)"},
+		},
+		{
+			name:        "version",
+			opts:        []Options{WithOptimisticConcurrency()},
+			inputFields: []string{"name", "version"},
+			contains: []string{
+				"expectedVersion := input.Version\ninput.Version = nil",
+				"req.AddVersion(1)",
+				"req.Where(control.VersionEQ(*expectedVersion))",
+				"generated.IsNotFound(err) && expectedVersion != nil",
+				`graphutils.CheckVersion("control", id, expectedVersion, current.Version)`,
+			},
+			notContains: []string{"res.Version)"},
+		},
+		{
+			name:        "expected updated at is used over version",
+			opts:        []Options{WithOptimisticConcurrency()},
+			inputFields: []string{"version", "expectedUpdatedAt"},
+			contains:    []string{"graphutils.CheckUpdatedAt"},
+			notContains: []string{"CheckVersion", "AddVersion"},
+		},
+		{
+			name:        "input without concurrency fields",
+			opts:        []Options{WithOptimisticConcurrency()},
+			inputFields: []string{"name"},
+			notContains: []string{"graphutils.Check", "req.Where"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(append(tc.opts, WithEntGeneratedPackage("github.com/example/ent/generated"))...)
+
+			rendered, err := plugin.renderUpdate(newUpdateField("UpdateControl", "ControlUpdatePayload", tc.inputFields...))
+			require.NoError(t, err)
+
+			assert.Contains(t, rendered, "res.Update().SetInput(input)")
+
+			for _, c := range tc.contains {
+				assert.Contains(t, rendered, c)
+			}
+
+			for _, c := range tc.notContains {
+				assert.NotContains(t, rendered, c)
+			}
+		})
+	}
+}
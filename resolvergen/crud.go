@@ -5,6 +5,7 @@ import (
 
 	"bytes"
 	"html/template"
+	"path"
 	"strings"
 
 	"github.com/99designs/gqlgen/codegen"
@@ -12,11 +13,18 @@ import (
 
 	"github.com/stoewer/go-strcase"
 	gqlast "github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 //go:embed templates/**.gotpl templates/**/**.gotpl
 var templates embed.FS
 
+// reserveImport reserves the import in the file currently being generated by gqlgen
+var reserveImport = func(path string, aliases ...string) (string, error) {
+	return gqltemplates.CurrentImports.Reserve(path, aliases...)
+}
+
 // crudResolver is a struct to hold the field for the CRUD resolver
 type crudResolver struct {
 	// Field is the field for the CRUD resolver
@@ -39,42 +47,66 @@ type crudResolver struct {
 	IncludeCustomUpdateFields bool
 	// ArchivableSchemas is a map of entity names that support archived status filtering
 	ArchivableSchemas map[string]bool
+	// SoftDeleteSchemas is a map of entity names that are soft deleted instead of removed
+	SoftDeleteSchemas map[string]bool
+	// Imports are the import paths of the runtime packages referenced by the templates
+	Imports runtimeimports.Config
+	// FieldTemplate is the name of the defined template used to implement a custom field instead of the base resolver
+	FieldTemplate string
+	// BulkOperation is the operation of the rows of a bulk or CSV bulk mutation, Create, Update, Delete or Upsert
+	BulkOperation string
+	// OptimisticConcurrency checks the expected updated at time or version in the update input against the stored object
+	OptimisticConcurrency bool
 }
 
-// renderTemplate renders the template with the given name
-func renderTemplate(templateName string, input *crudResolver, childTemplates []string) string {
-	patterns := []string{"templates/" + templateName}
+// renderTemplate renders the template with the given name from the template filesystem of the plugin,
+// parse and execute failures are returned as a TemplateError for the field being rendered
+func (r *ResolverPlugin) renderTemplate(templateName string, input *crudResolver, childTemplates []string) (string, error) {
+	patterns := []string{templateName}
+	patterns = append(patterns, childTemplates...)
 
-	for _, child := range childTemplates {
-		patterns = append(patterns, "templates/"+child)
-	}
+	// templates are named after the file name, so use the base name to match the parsed template
+	t := template.New(path.Base(templateName))
 
-	t, err := template.New(templateName).Funcs(template.FuncMap{
+	t, err := t.Funcs(template.FuncMap{
 		"getEntityName":           getEntityName,
+		"entityName":              r.fieldEntityName,
+		"isBulkJobField":          r.isBulkJobField,
 		"getInputObjectName":      getInputObjectName,
 		"toLower":                 strings.ToLower,
 		"toLowerCamel":            strcase.LowerCamelCase,
 		"hasArgument":             hasArgument,
 		"isListType":              isListType,
-		"hasOwnerField":           hasOwnerField,
-		"reserveImport":           gqltemplates.CurrentImports.Reserve,
+		"hasOwnerField":           r.hasOwnerField,
+		"reserveImport":           reserveImport,
 		"modelPackage":            modelPackage,
 		"isCommentUpdateOnObject": isCommentUpdateOnObject,
 		"contains":                strings.Contains,
 		"hasStatusField":          func(entityName string) bool { return input.ArchivableSchemas[entityName] },
 		"getArchivedStatusValue":  getArchivedStatusEnum,
-	}).ParseFS(templates, patterns...)
+		"hasInputField":           hasInputField,
+		"isSoftDelete":            func(entityName string) bool { return input.SoftDeleteSchemas[entityName] },
+		"hasTemplate":             func(name string) bool { return t.Lookup(name) != nil },
+		"include": func(name string, data any) (template.HTML, error) {
+			var buf bytes.Buffer
+			if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+				return "", err
+			}
+
+			return template.HTML(buf.String()), nil // nolint:gosec
+		},
+	}).ParseFS(r.templateFS(), patterns...)
 	if err != nil {
-		panic(err)
+		return "", newTemplateError(input.Field, templateName, err)
 	}
 
 	var code bytes.Buffer
 
 	if err = t.Execute(&code, input); err != nil {
-		panic(err)
+		return "", newTemplateError(input.Field, templateName, err)
 	}
 
-	return strings.Trim(code.String(), "\t \n")
+	return strings.Trim(code.String(), "\t \n"), nil
 }
 
 func modelPackage(modelPackage string) string {
@@ -99,8 +131,8 @@ func getEntPackageFromImport(importPath string) string {
 }
 
 // renderCreate renders the create template
-func (r *ResolverPlugin) renderCreate(field *codegen.Field) string {
-	return renderTemplate("create.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderCreate(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, CreateOperation), &crudResolver{
 		Field:                     field,
 		ModelPackage:              r.modelPackage,
 		EntImport:                 r.entGeneratedPackage,
@@ -108,11 +140,12 @@ func (r *ResolverPlugin) renderCreate(field *codegen.Field) string {
 		GraphQLImport:             r.graphqlImport,
 		IncludeCustomUpdateFields: r.includeCustomFields,
 		ArchivableSchemas:         r.archivableSchemas,
+		Imports:                   r.runtimeImports.WithDefaults(),
 	}, []string{})
 }
 
 // renderUpdate renders the update template
-func (r *ResolverPlugin) renderUpdate(field *codegen.Field) string {
+func (r *ResolverPlugin) renderUpdate(field *codegen.Field) (string, error) {
 	appendFields := getAppendFields(field)
 
 	cr := &crudResolver{
@@ -124,14 +157,17 @@ func (r *ResolverPlugin) renderUpdate(field *codegen.Field) string {
 		GraphQLImport:             r.graphqlImport,
 		IncludeCustomUpdateFields: r.includeCustomFields,
 		ArchivableSchemas:         r.archivableSchemas,
+		Imports:                   r.runtimeImports.WithDefaults(),
+		FieldTemplate:             r.fieldTemplate(field.GoFieldName),
+		OptimisticConcurrency:     r.optimisticConcurrency,
 	}
 
-	return renderTemplate("update.gotpl", cr, []string{"updatefields/*.gotpl"})
+	return r.renderTemplate(r.operationTemplate(field, UpdateOperation), cr, []string{"updatefields/*.gotpl"})
 }
 
 // renderDelete renders the delete template
-func (r *ResolverPlugin) renderDelete(field *codegen.Field) string {
-	return renderTemplate("delete.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderDelete(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, DeleteOperation), &crudResolver{
 		Field:                     field,
 		ModelPackage:              r.modelPackage,
 		EntImport:                 r.entGeneratedPackage,
@@ -139,13 +175,45 @@ func (r *ResolverPlugin) renderDelete(field *codegen.Field) string {
 		GraphQLImport:             r.graphqlImport,
 		IncludeCustomUpdateFields: r.includeCustomFields,
 		ArchivableSchemas:         r.archivableSchemas,
+		SoftDeleteSchemas:         r.softDeleteSchemas,
+		Imports:                   r.runtimeImports.WithDefaults(),
+		FieldTemplate:             r.fieldTemplate(field.GoFieldName),
 	}, []string{"deletefields/*.gotpl"})
 }
 
+// renderRestore renders the restore template for soft deleted schemas
+func (r *ResolverPlugin) renderRestore(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, RestoreOperation), &crudResolver{
+		Field:             field,
+		ModelPackage:      r.modelPackage,
+		EntImport:         r.entGeneratedPackage,
+		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
+		GraphQLImport:     r.graphqlImport,
+		ArchivableSchemas: r.archivableSchemas,
+		SoftDeleteSchemas: r.softDeleteSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
+	}, []string{})
+}
+
+// renderArchive renders the archive or unarchive template for archivable schemas
+func (r *ResolverPlugin) renderArchive(field *codegen.Field, operation string) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, operation), &crudResolver{
+		Field:             field,
+		ModelPackage:      r.modelPackage,
+		EntImport:         r.entGeneratedPackage,
+		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
+		GraphQLImport:     r.graphqlImport,
+		ArchivableSchemas: r.archivableSchemas,
+		SoftDeleteSchemas: r.softDeleteSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
+	}, []string{})
+}
+
 // renderBulkUpload renders the bulk upload template
-func (r *ResolverPlugin) renderBulkUpload(field *codegen.Field) string {
-	return renderTemplate("upload.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderBulkUpload(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, r.crudType(field)), &crudResolver{
 		Field:               field,
+		BulkOperation:       r.bulkOperation(field),
 		ModelPackage:        r.modelPackage,
 		EntImport:           r.entGeneratedPackage,
 		EntPackage:          getEntPackageFromImport(r.entGeneratedPackage),
@@ -153,60 +221,100 @@ func (r *ResolverPlugin) renderBulkUpload(field *codegen.Field) string {
 		CSVGeneratedImport:  r.csvGeneratedPackage,
 		CSVGeneratedPackage: getEntPackageFromImport(r.csvGeneratedPackage),
 		ArchivableSchemas:   r.archivableSchemas,
+		Imports:             r.runtimeImports.WithDefaults(),
 	}, []string{})
 }
 
 // renderBulk renders the bulk template
-func (r *ResolverPlugin) renderBulk(field *codegen.Field) string {
-	return renderTemplate("bulk.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderBulk(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, BulkOperation), &crudResolver{
 		Field:             field,
+		BulkOperation:     r.bulkOperation(field),
 		ModelPackage:      r.modelPackage,
 		EntImport:         r.entGeneratedPackage,
 		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
 		GraphQLImport:     r.graphqlImport,
 		ArchivableSchemas: r.archivableSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
 	}, []string{})
 }
 
 // renderQuery renders the query template
-func (r *ResolverPlugin) renderQuery(field *codegen.Field) string {
-	return renderTemplate("get.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderQuery(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, GetOperation), &crudResolver{
 		Field:             field,
 		ModelPackage:      r.modelPackage,
 		EntImport:         r.entGeneratedPackage,
 		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
 		GraphQLImport:     r.graphqlImport,
 		ArchivableSchemas: r.archivableSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
+	}, []string{})
+}
+
+// renderExport renders the CSV export template
+func (r *ResolverPlugin) renderExport(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, ExportOperation), &crudResolver{
+		Field:             field,
+		EntImport:         r.entGeneratedPackage,
+		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
+		ArchivableSchemas: r.archivableSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
+	}, []string{})
+}
+
+// renderBulkJob renders the template of the query that returns the status of a bulk job
+func (r *ResolverPlugin) renderBulkJob(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, BulkJobOperation), &crudResolver{
+		Field:   field,
+		Imports: r.runtimeImports.WithDefaults(),
 	}, []string{})
 }
 
 // renderList renders the list template
-func (r *ResolverPlugin) renderList(field *codegen.Field) string {
-	return renderTemplate("list.gotpl", &crudResolver{
+func (r *ResolverPlugin) renderList(field *codegen.Field) (string, error) {
+	return r.renderTemplate(r.operationTemplate(field, ListOperation), &crudResolver{
 		Field:             field,
 		EntImport:         r.entGeneratedPackage,
 		EntPackage:        getEntPackageFromImport(r.entGeneratedPackage),
 		GraphQLImport:     r.graphqlImport,
 		ArchivableSchemas: r.archivableSchemas,
+		Imports:           r.runtimeImports.WithDefaults(),
 	}, []string{})
 }
 
 const (
-	CreateOperation  = "Create"
-	UpdateOperation  = "Update"
-	AddOperation     = "Add"
-	DeleteOperation  = "Delete"
-	InputObject      = "Input"
-	BulkOperation    = "Bulk"
-	CSVOperation     = "CSV"
-	BulkCSVOperation = "BulkCSV"
-	UploadOperation  = "Upload"
-	Connection       = "Connection"
-	Payload          = "Payload"
+	CreateOperation    = "Create"
+	UpdateOperation    = "Update"
+	AddOperation       = "Add"
+	DeleteOperation    = "Delete"
+	RestoreOperation   = "Restore"
+	ArchiveOperation   = "Archive"
+	UnarchiveOperation = "Unarchive"
+	InputObject        = "Input"
+	BulkOperation      = "Bulk"
+	CSVOperation       = "CSV"
+	BulkCSVOperation   = "BulkCSV"
+	UploadOperation    = "Upload"
+	GetOperation       = "Get"
+	ListOperation      = "List"
+	Connection         = "Connection"
+	Payload            = "Payload"
+	// UpsertOperation and CreateOrUpdateOperation are used in the names of the bulk upsert mutations,
+	// they are implemented with the Bulk and BulkCSV templates
+	UpsertOperation         = "Upsert"
+	CreateOrUpdateOperation = "CreateOrUpdate"
+	// ExportOperation is used in the names of the CSV export queries, e.g. exportControlCSV
+	ExportOperation = "Export"
+	// BulkJobOperation is used for the queries that return the status of a bulk job, e.g. bulkJob
+	BulkJobOperation = "BulkJob"
+	// bulkJobSuffix is the suffix of the job variants of the CSV bulk mutations, e.g. createBulkCSVControlJob
+	bulkJobSuffix = "Job"
 )
 
-// crudTypes is a list of CRUD operations that are included in the resolver name
-var stripStrings = []string{CreateOperation, UpdateOperation, DeleteOperation, BulkOperation, CSVOperation, UploadOperation, Connection, Payload}
+// crudTypes is a list of CRUD operations that are included in the resolver name,
+// UnarchiveOperation must be stripped before ArchiveOperation, which it contains
+var stripStrings = []string{CreateOperation, UpdateOperation, DeleteOperation, UpsertOperation, RestoreOperation, UnarchiveOperation, ArchiveOperation, BulkOperation, CSVOperation, UploadOperation, Connection, Payload}
 
 // getEntityName returns the entity name by stripping the CRUD operation from the resolver name
 func getEntityName(name string) string {
@@ -234,17 +342,31 @@ func isListType(arg string, args gqlast.ArgumentDefinitionList) bool {
 }
 
 // hasOwnerField checks if the field has an owner field in the input arguments
-func hasOwnerField(field *codegen.Field) bool {
-	if crudType(field) == CreateOperation {
+func (r *ResolverPlugin) hasOwnerField(field *codegen.Field) bool {
+	if r.crudType(field) == CreateOperation {
 		return argsHasOwnerID(field.Args)
 	}
 
 	// check the input of the create, instead of the update since its immutable
 	checkFieldName := strings.Replace(field.Name, "update", "create", 1)
 
-	// remove the Bulk and BulkCSV from fields
-	checkFieldName = strings.ReplaceAll(checkFieldName, BulkOperation, "")
-	checkFieldName = strings.ReplaceAll(checkFieldName, CSVOperation, "")
+	if matched, ok, _ := r.bulkMutationName(field); ok {
+		// the bulk mutations matched by the mutation name patterns, and their job variants, use the create input
+		checkFieldName = "create" + matched.Object
+	} else {
+		// the bulk upsert mutations use the create input
+		checkFieldName = strings.Replace(checkFieldName, "upsert", "create", 1)
+		checkFieldName = strings.Replace(checkFieldName, "createOrUpdate", "create", 1)
+
+		// the job variants use the input of the CSV bulk mutation
+		if r.isBulkJobField(field) {
+			checkFieldName = strings.TrimSuffix(checkFieldName, bulkJobSuffix)
+		}
+
+		// remove the Bulk and BulkCSV from fields
+		checkFieldName = strings.ReplaceAll(checkFieldName, BulkOperation, "")
+		checkFieldName = strings.ReplaceAll(checkFieldName, CSVOperation, "")
+	}
 
 	if field.Object.HasField(checkFieldName) {
 		for _, obj := range field.Object.Fields {
@@ -286,6 +408,17 @@ func getAppendFields(field *codegen.Field) (appendFields []string) {
 	return
 }
 
+// hasInputField checks if the input object argument of the field has a field with the given name
+func hasInputField(field *codegen.Field, name string) bool {
+	for _, arg := range field.Args {
+		if arg.TypeReference.Definition.Kind == gqlast.InputObject && arg.TypeReference.Definition.Fields.ForName(name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 // isCommentUpdateOnObject checks if the field is of the format "Update<Something>Comment"
 func isCommentUpdateOnObject(field string) bool {
 	if strings.Contains(field, UpdateOperation) && strings.Contains(field, "Comment") {
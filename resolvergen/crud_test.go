@@ -74,6 +74,11 @@ func TestGetEntityName(t *testing.T) {
 			input:    "BulkCSVOrder",
 			expected: "Order",
 		},
+		{
+			name:     "strip Upsert payload",
+			input:    "ProductBulkUpsertPayload",
+			expected: "Product",
+		},
 		{
 			name:     "strip Connection",
 			input:    "UserConnection",
@@ -0,0 +1,208 @@
+package resolvergen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/stoewer/go-strcase"
+)
+
+const (
+	// CRUDDirective is the name of the schema directive used to explicitly set the operation
+	// and entity of a resolver field instead of relying on the field name, e.g.
+	//
+	//	createDeleteRequest(input: CreateDeleteRequestInput!): DeleteRequestCreatePayload! @crud(op: CREATE, entity: "DeleteRequest")
+	//
+	// the directive must be declared in the schema and should be marked as skip_runtime in the gqlgen config:
+	//
+	//	enum CRUDOperation {
+	//	  CREATE UPDATE DELETE RESTORE ARCHIVE UNARCHIVE BULK BULK_CSV UPLOAD GET LIST EXPORT BULK_JOB
+	//	  BULK_CREATE BULK_UPDATE BULK_DELETE BULK_UPSERT BULK_CSV_CREATE BULK_CSV_UPDATE BULK_CSV_UPSERT
+	//	}
+	//	directive @crud(op: CRUDOperation!, entity: String) on FIELD_DEFINITION
+	//
+	// BULK and BULK_CSV take the operation of the rows from the field name without the entity name,
+	// the BULK_<op> and BULK_CSV_<op> values set it explicitly
+	CRUDDirective = "crud"
+
+	crudDirectiveOpArg     = "op"
+	crudDirectiveEntityArg = "entity"
+)
+
+// directiveOperations maps the values of the op argument on the crud directive to the operation type
+var directiveOperations = map[string]string{
+	"CREATE":    CreateOperation,
+	"UPDATE":    UpdateOperation,
+	"DELETE":    DeleteOperation,
+	"RESTORE":   RestoreOperation,
+	"ARCHIVE":   ArchiveOperation,
+	"UNARCHIVE": UnarchiveOperation,
+	"BULK":      BulkOperation,
+	"BULK_CSV":  BulkCSVOperation,
+	"UPLOAD":    UploadOperation,
+	"GET":       GetOperation,
+	"LIST":      ListOperation,
+	"EXPORT":    ExportOperation,
+	"BULK_JOB":  BulkJobOperation,
+}
+
+// directiveBulkOperations maps the values of the op argument on the crud directive that also set the operation of
+// the rows of a bulk or CSV bulk mutation to the operation type and the bulk operation
+var directiveBulkOperations = map[string]crudDirective{
+	"BULK_CREATE":     {Operation: BulkOperation, BulkOperation: CreateOperation},
+	"BULK_UPDATE":     {Operation: BulkOperation, BulkOperation: UpdateOperation},
+	"BULK_DELETE":     {Operation: BulkOperation, BulkOperation: DeleteOperation},
+	"BULK_UPSERT":     {Operation: BulkOperation, BulkOperation: UpsertOperation},
+	"BULK_CSV_CREATE": {Operation: BulkCSVOperation, BulkOperation: CreateOperation},
+	"BULK_CSV_UPDATE": {Operation: BulkCSVOperation, BulkOperation: UpdateOperation},
+	"BULK_CSV_UPSERT": {Operation: BulkCSVOperation, BulkOperation: UpsertOperation},
+}
+
+// crudDirective holds the arguments of the crud directive set on a field
+type crudDirective struct {
+	// Operation is the operation type the field should be implemented with
+	Operation string
+	// BulkOperation is the operation of the rows of a bulk or CSV bulk mutation, empty when it is taken from the field name
+	BulkOperation string
+	// Entity is the entity name used in the templates, if empty it is derived from the field type
+	Entity string
+}
+
+// getCRUDDirective returns the crud directive set on the field, or nil if the field has no directive
+func getCRUDDirective(f *codegen.Field) (*crudDirective, error) {
+	if f == nil || f.FieldDefinition == nil {
+		return nil, nil
+	}
+
+	d := f.FieldDefinition.Directives.ForName(CRUDDirective)
+	if d == nil {
+		return nil, nil
+	}
+
+	opArg := d.Arguments.ForName(crudDirectiveOpArg)
+	if opArg == nil || opArg.Value == nil {
+		return nil, fmt.Errorf("%w: %s is missing the %s argument", ErrInvalidCRUDDirective, f.Name, crudDirectiveOpArg)
+	}
+
+	directive := &crudDirective{}
+
+	if op, ok := directiveOperations[strings.ToUpper(opArg.Value.Raw)]; ok {
+		directive.Operation = op
+	} else if bulk, ok := directiveBulkOperations[strings.ToUpper(opArg.Value.Raw)]; ok {
+		directive.Operation = bulk.Operation
+		directive.BulkOperation = bulk.BulkOperation
+	} else {
+		return nil, fmt.Errorf("%w: %s has unknown operation %q", ErrInvalidCRUDDirective, f.Name, opArg.Value.Raw)
+	}
+
+	if entityArg := d.Arguments.ForName(crudDirectiveEntityArg); entityArg != nil && entityArg.Value != nil {
+		directive.Entity = entityArg.Value.Raw
+	}
+
+	return directive, nil
+}
+
+// fieldEntityName returns the entity name for the field, using the entity set on the crud directive
+// when present and falling back to stripping the operation from the return type name. CSV export
+// queries return a string and the job variants of the CSV bulk mutations return a bulk job, so the
+// entity is taken from the field name instead, and the bulk mutations use the entity matched by the
+// mutation name patterns
+func (r *ResolverPlugin) fieldEntityName(f *codegen.Field) string {
+	if d, err := getCRUDDirective(f); err == nil && d != nil && d.Entity != "" {
+		return d.Entity
+	}
+
+	if isCSVExportField(f.GoFieldName) {
+		return f.GoFieldName[len(ExportOperation) : len(f.GoFieldName)-len(CSVOperation)]
+	}
+
+	if matched, ok, _ := r.bulkMutationName(f); ok {
+		return matched.Object
+	}
+
+	if r.isBulkJobField(f) {
+		return getEntityName(strings.TrimSuffix(f.GoFieldName, bulkJobSuffix))
+	}
+
+	return getEntityName(f.TypeReference.Definition.Name)
+}
+
+// bulkOperation returns the operation of the rows of a bulk or CSV bulk mutation, Create, Update, Delete or Upsert.
+// The operation set on the crud directive is used when present, then the operation matched by the mutation name
+// patterns, otherwise it is taken from the field name without the entity name, so createBulkDeleteRequest with
+// @crud(op: BULK, entity: "DeleteRequest") is a bulk create
+func (r *ResolverPlugin) bulkOperation(f *codegen.Field) string {
+	if d, err := getCRUDDirective(f); err == nil && d != nil && d.BulkOperation != "" {
+		return d.BulkOperation
+	}
+
+	if matched, ok, _ := r.bulkMutationName(f); ok {
+		return strcase.UpperCamelCase(matched.Operation)
+	}
+
+	name := strings.Replace(f.GoFieldName, r.fieldEntityName(f), "", 1)
+
+	switch {
+	case strings.Contains(name, CreateOrUpdateOperation), strings.Contains(name, UpsertOperation):
+		return UpsertOperation
+	case strings.Contains(name, DeleteOperation):
+		return DeleteOperation
+	case strings.Contains(name, UpdateOperation):
+		return UpdateOperation
+	default:
+		return CreateOperation
+	}
+}
+
+// nameOperationKeywords are the keywords checked by crudType, in the same order, when classifying by field name
+var nameOperationKeywords = []string{UnarchiveOperation, ArchiveOperation, RestoreOperation, CSVOperation, BulkOperation, UploadOperation, CreateOperation, UpdateOperation, AddOperation, DeleteOperation}
+
+// operationVerbs are the keywords that describe what a mutation does to an entity, a name should only contain one
+var operationVerbs = []string{CreateOperation, UpdateOperation, AddOperation, DeleteOperation, UpsertOperation, RestoreOperation, ArchiveOperation, UnarchiveOperation}
+
+// isAmbiguousOperationName returns true when the name based classification in crudType can not be trusted,
+// either because the name contains more than one operation verb (e.g. CreateDeleteRequest)
+// or because the keyword used for the classification only matches part of a word (e.g. UpdateBulkheadConfig)
+func isAmbiguousOperationName(name string) bool {
+	// CreateOrUpdate is a single verb, used by the bulk CSV upsert mutations
+	name = strings.ReplaceAll(name, CreateOrUpdateOperation, UpsertOperation)
+
+	verbs := 0
+
+	for _, verb := range operationVerbs {
+		if containsWord(name, verb) {
+			verbs++
+		}
+	}
+
+	if verbs > 1 {
+		return true
+	}
+
+	for _, keyword := range nameOperationKeywords {
+		if strings.Contains(name, keyword) {
+			return !containsWord(name, keyword)
+		}
+	}
+
+	return false
+}
+
+// containsWord checks if the camel case name contains the word, not just as the start of a longer word
+// for example UpdateBulkTask contains the word Bulk, but UpdateBulkheadConfig does not
+func containsWord(name, word string) bool {
+	for i := 0; i+len(word) <= len(name); i++ {
+		if name[i:i+len(word)] != word {
+			continue
+		}
+
+		end := i + len(word)
+		if end == len(name) || !unicode.IsLower(rune(name[end])) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,412 @@
+package resolvergen
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/bulkgen"
+)
+
+// newCRUDField returns a mutation field with the given name, return type and directives for testing
+func newCRUDField(goFieldName, returnType string, directives ...*ast.Directive) *codegen.Field {
+	return &codegen.Field{
+		FieldDefinition: &ast.FieldDefinition{
+			Name:       goFieldName,
+			Directives: directives,
+		},
+		GoFieldName: goFieldName,
+		TypeReference: &config.TypeReference{
+			Definition: &ast.Definition{Name: returnType, Kind: ast.Object},
+		},
+		Object: &codegen.Object{
+			Definition: &ast.Definition{Name: string(ast.Mutation)},
+		},
+	}
+}
+
+// newCRUDDirective returns a crud directive with the given op and entity arguments, empty values are omitted
+func newCRUDDirective(op, entity string) *ast.Directive {
+	d := &ast.Directive{Name: CRUDDirective}
+
+	if op != "" {
+		d.Arguments = append(d.Arguments, &ast.Argument{Name: crudDirectiveOpArg, Value: &ast.Value{Raw: op, Kind: ast.EnumValue}})
+	}
+
+	if entity != "" {
+		d.Arguments = append(d.Arguments, &ast.Argument{Name: crudDirectiveEntityArg, Value: &ast.Value{Raw: entity, Kind: ast.StringValue}})
+	}
+
+	return d
+}
+
+func TestGetCRUDDirective(t *testing.T) {
+	testCases := []struct {
+		name        string
+		field       *codegen.Field
+		expected    *crudDirective
+		expectedErr error
+	}{
+		{
+			name:  "no directive",
+			field: newCRUDField("CreateTask", "TaskCreatePayload"),
+		},
+		{
+			name:  "nil field definition",
+			field: &codegen.Field{GoFieldName: "CreateTask"},
+		},
+		{
+			name:     "operation and entity",
+			field:    newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload", newCRUDDirective("CREATE", "DeleteRequest")),
+			expected: &crudDirective{Operation: CreateOperation, Entity: "DeleteRequest"},
+		},
+		{
+			name:     "operation only",
+			field:    newCRUDField("UpdateBulkheadConfig", "BulkheadConfigUpdatePayload", newCRUDDirective("UPDATE", "")),
+			expected: &crudDirective{Operation: UpdateOperation},
+		},
+		{
+			name:     "bulk csv operation",
+			field:    newCRUDField("ImportTasks", "TaskBulkCreatePayload", newCRUDDirective("BULK_CSV", "Task")),
+			expected: &crudDirective{Operation: BulkCSVOperation, Entity: "Task"},
+		},
+		{
+			name:     "bulk operation",
+			field:    newCRUDField("ImportDeleteRequests", "DeleteRequestBulkCreatePayload", newCRUDDirective("BULK_CREATE", "DeleteRequest")),
+			expected: &crudDirective{Operation: BulkOperation, BulkOperation: CreateOperation, Entity: "DeleteRequest"},
+		},
+		{
+			name:     "bulk csv update operation",
+			field:    newCRUDField("ReplaceTasksFromFile", "TaskBulkUpdatePayload", newCRUDDirective("BULK_CSV_UPDATE", "Task")),
+			expected: &crudDirective{Operation: BulkCSVOperation, BulkOperation: UpdateOperation, Entity: "Task"},
+		},
+		{
+			name:        "missing operation",
+			field:       newCRUDField("CreateTask", "TaskCreatePayload", newCRUDDirective("", "Task")),
+			expectedErr: ErrInvalidCRUDDirective,
+		},
+		{
+			name:        "unknown operation",
+			field:       newCRUDField("CreateTask", "TaskCreatePayload", newCRUDDirective("MERGE", "Task")),
+			expectedErr: ErrInvalidCRUDDirective,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := getCRUDDirective(tc.field)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, res)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestCrudTypeWithDirective(t *testing.T) {
+	testCases := []struct {
+		name     string
+		field    *codegen.Field
+		expected string
+	}{
+		{
+			name:     "name heuristic without directive",
+			field:    newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload"),
+			expected: CreateOperation,
+		},
+		{
+			name:     "name heuristic picks bulk for partial word",
+			field:    newCRUDField("UpdateBulkheadConfig", "BulkheadConfigUpdatePayload"),
+			expected: BulkOperation,
+		},
+		{
+			name:     "directive overrides name",
+			field:    newCRUDField("UpdateBulkheadConfig", "BulkheadConfigUpdatePayload", newCRUDDirective("UPDATE", "BulkheadConfig")),
+			expected: UpdateOperation,
+		},
+		{
+			name:     "directive on name without keywords",
+			field:    newCRUDField("ArchiveTask", "TaskDeletePayload", newCRUDDirective("DELETE", "Task")),
+			expected: DeleteOperation,
+		},
+		{
+			name:     "invalid directive falls back to name",
+			field:    newCRUDField("DeleteTask", "TaskDeletePayload", newCRUDDirective("REMOVE", "Task")),
+			expected: DeleteOperation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().crudType(tc.field))
+		})
+	}
+}
+
+func TestBulkOperation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		field    *codegen.Field
+		expected string
+	}{
+		{
+			name:     "bulk create",
+			field:    newCRUDField("CreateBulkTask", "TaskBulkCreatePayload"),
+			expected: CreateOperation,
+		},
+		{
+			name:     "bulk delete",
+			field:    newCRUDField("DeleteBulkTask", "TaskBulkDeletePayload"),
+			expected: DeleteOperation,
+		},
+		{
+			name:     "csv bulk update",
+			field:    newCRUDField("UpdateBulkCSVTask", "TaskBulkUpdatePayload"),
+			expected: UpdateOperation,
+		},
+		{
+			name:     "csv bulk create or update",
+			field:    newCRUDField("CreateOrUpdateBulkCSVTask", "TaskBulkUpsertPayload"),
+			expected: UpsertOperation,
+		},
+		{
+			name:     "operation in the entity name",
+			field:    newCRUDField("CreateBulkDeleteRequest", "DeleteRequestBulkCreatePayload", newCRUDDirective("BULK", "DeleteRequest")),
+			expected: CreateOperation,
+		},
+		{
+			name:     "operation from directive",
+			field:    newCRUDField("PurgeTasks", "TaskBulkDeletePayload", newCRUDDirective("BULK_DELETE", "Task")),
+			expected: DeleteOperation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().bulkOperation(tc.field))
+		})
+	}
+}
+
+func TestRenderBulkOperationFromDirective(t *testing.T) {
+	stubReserveImport(t)
+
+	field := newCRUDField("CreateBulkDeleteRequest", "DeleteRequestBulkCreatePayload", newCRUDDirective("BULK", "DeleteRequest"))
+
+	rendered, err := NewWithOptions().renderBulk(field)
+	require.NoError(t, err)
+
+	assert.Contains(t, rendered, "return r.bulkCreateDeleteRequest(ctx, input)")
+	assert.NotContains(t, rendered, "bulkDeleteDeleteRequest")
+
+	field = newCRUDField("PurgeTasks", "TaskBulkDeletePayload", newCRUDDirective("BULK_DELETE", "Task"))
+
+	rendered, err = NewWithOptions().renderBulk(field)
+	require.NoError(t, err)
+
+	assert.Contains(t, rendered, "return r.bulkDeleteTask(ctx, ids)")
+}
+
+func TestMutationNamePatterns(t *testing.T) {
+	plugin := NewWithOptions(WithMutationNamePatterns("{op}Many{Entity}", "{entity}BatchCreate", "{op}ManyFromCSV{Entity}"))
+
+	testCases := []struct {
+		name              string
+		field             *codegen.Field
+		expectedOp        string
+		expectedBulkOp    string
+		expectedEntity    string
+		expectedBulkField bool
+	}{
+		{
+			name:              "bulk create",
+			field:             newCRUDField("CreateManyControl", "ControlManyPayload"),
+			expectedOp:        BulkOperation,
+			expectedBulkOp:    CreateOperation,
+			expectedEntity:    "Control",
+			expectedBulkField: true,
+		},
+		{
+			name:              "bulk delete",
+			field:             newCRUDField("DeleteManyInternalPolicy", "InternalPolicyManyDeletePayload"),
+			expectedOp:        BulkOperation,
+			expectedBulkOp:    DeleteOperation,
+			expectedEntity:    "InternalPolicy",
+			expectedBulkField: true,
+		},
+		{
+			name:              "lower case entity with the operation in the pattern",
+			field:             newCRUDField("ControlBatchCreate", "ControlBatchPayload"),
+			expectedOp:        BulkOperation,
+			expectedBulkOp:    CreateOperation,
+			expectedEntity:    "Control",
+			expectedBulkField: true,
+		},
+		{
+			name:              "CSV bulk upsert",
+			field:             newCRUDField("CreateOrUpdateManyFromCSVControl", "ControlManyPayload"),
+			expectedOp:        BulkCSVOperation,
+			expectedBulkOp:    UpsertOperation,
+			expectedEntity:    "Control",
+			expectedBulkField: true,
+		},
+		{
+			name:           "single create",
+			field:          newCRUDField("CreateControl", "ControlCreatePayload"),
+			expectedOp:     CreateOperation,
+			expectedEntity: "Control",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedOp, plugin.crudType(tc.field))
+			assert.Equal(t, tc.expectedEntity, plugin.fieldEntityName(tc.field))
+			assert.Equal(t, tc.expectedBulkField, plugin.isBulkMutationResolver(tc.field))
+
+			if tc.expectedBulkField {
+				assert.Equal(t, tc.expectedBulkOp, plugin.bulkOperation(tc.field))
+			}
+		})
+	}
+
+	t.Run("dry run of a custom bulk mutation", func(t *testing.T) {
+		field := withDryRunArgument(newCRUDField("CreateManyControl", "ControlManyPayload"), ast.NamedType("Boolean", nil))
+		assert.True(t, plugin.ignoresDryRun("return r.bulkCreateControl(ctx, input)", field))
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		err := NewWithOptions(WithMutationNamePatterns("{op}Many")).GenerateCode(&codegen.Data{})
+		require.ErrorIs(t, err, bulkgen.ErrInvalidMutationNamePattern)
+	})
+}
+
+func TestCheckOperationClassificationAmbiguousMutationName(t *testing.T) {
+	plugin := NewWithOptions(WithMutationNamePatterns("{op}Many{Entity}", "createMany{Entity}"))
+	plugin.checkOperationClassification(newCRUDField("CreateManyControl", "ControlManyPayload"))
+
+	require.Len(t, plugin.Warnings(), 1)
+	assert.Contains(t, plugin.Warnings()[0], "CreateManyControl matches the patterns")
+}
+
+func TestFieldEntityName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		field    *codegen.Field
+		expected string
+	}{
+		{
+			name:     "entity from return type",
+			field:    newCRUDField("CreateTask", "TaskCreatePayload"),
+			expected: "Task",
+		},
+		{
+			name:     "entity from directive",
+			field:    newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload", newCRUDDirective("CREATE", "DeleteRequest")),
+			expected: "DeleteRequest",
+		},
+		{
+			name:     "directive without entity uses return type",
+			field:    newCRUDField("ArchiveTask", "TaskUpdatePayload", newCRUDDirective("UPDATE", "")),
+			expected: "Task",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().fieldEntityName(tc.field))
+		})
+	}
+}
+
+func TestIsAmbiguousOperationName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "simple create",
+			input:    "CreateTask",
+			expected: false,
+		},
+		{
+			name:     "bulk csv create",
+			input:    "CreateBulkCSVTask",
+			expected: false,
+		},
+		{
+			name:     "comment update",
+			input:    "UpdateTaskComment",
+			expected: false,
+		},
+		{
+			name:     "add as part of a word",
+			input:    "UpdateAddress",
+			expected: false,
+		},
+		{
+			name:     "two verbs",
+			input:    "CreateDeleteRequest",
+			expected: true,
+		},
+		{
+			name:     "bulk as part of a word",
+			input:    "UpdateBulkheadConfig",
+			expected: true,
+		},
+		{
+			name:     "upload as part of a word",
+			input:    "CreateUploadedFile",
+			expected: true,
+		},
+		{
+			name:     "no keywords",
+			input:    "ArchiveTask",
+			expected: false,
+		},
+		{
+			name:     "bulk upsert",
+			input:    "UpsertBulkTask",
+			expected: false,
+		},
+		{
+			name:     "bulk csv create or update",
+			input:    "CreateOrUpdateBulkCSVTask",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isAmbiguousOperationName(tc.input))
+		})
+	}
+}
+
+func TestCheckOperationClassification(t *testing.T) {
+	plugin := New()
+
+	plugin.checkOperationClassification(newCRUDField("CreateTask", "TaskCreatePayload"))
+	assert.Empty(t, plugin.Warnings())
+
+	plugin.checkOperationClassification(newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload", newCRUDDirective("CREATE", "DeleteRequest")))
+	assert.Empty(t, plugin.Warnings())
+
+	plugin.checkOperationClassification(newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload"))
+	assert.Len(t, plugin.Warnings(), 1)
+	assert.Contains(t, plugin.Warnings()[0], "CreateDeleteRequest")
+
+	plugin.checkOperationClassification(newCRUDField("DeleteTask", "TaskDeletePayload", newCRUDDirective("REMOVE", "")))
+	assert.Len(t, plugin.Warnings(), 2)
+	assert.Contains(t, plugin.Warnings()[1], ErrInvalidCRUDDirective.Error())
+}
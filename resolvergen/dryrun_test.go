@@ -0,0 +1,215 @@
+package resolvergen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// withDryRunArgument adds a dryRun argument of the type to the field
+func withDryRunArgument(field *codegen.Field, t *ast.Type) *codegen.Field {
+	field.FieldDefinition.Arguments = append(field.FieldDefinition.Arguments, &ast.ArgumentDefinition{Name: "dryRun", Type: t})
+
+	return field
+}
+
+func TestRenderBulkDryRun(t *testing.T) {
+	stubReserveImport(t)
+
+	csvOpts := []Options{WithCSVGeneratedPackage("github.com/example/app/internal/ent/csvgenerated")}
+
+	testCases := []struct {
+		name       string
+		field      string
+		returnType string
+		opts       []Options
+		upload     bool
+		dryRunType *ast.Type
+		dryRun     string
+		save       string
+	}{
+		{
+			name:       "bulk create",
+			field:      "CreateBulkControl",
+			returnType: "ControlBulkCreatePayload",
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkCreateControl(ctx, input)",
+			save:       "return r.bulkCreateControl(ctx, input)",
+		},
+		{
+			name:       "bulk update with a required argument",
+			field:      "UpdateBulkControl",
+			returnType: "ControlBulkUpdatePayload",
+			dryRunType: ast.NonNullNamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkUpdateControl(ctx, ids, input)",
+			save:       "return r.bulkUpdateControl(ctx, ids, input)",
+		},
+		{
+			name:       "bulk delete",
+			field:      "DeleteBulkControl",
+			returnType: "ControlBulkDeletePayload",
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkDeleteControl(ctx, ids)",
+			save:       "return r.bulkDeleteControl(ctx, ids)",
+		},
+		{
+			name:       "bulk upsert",
+			field:      "UpsertBulkControl",
+			returnType: "ControlBulkUpsertPayload",
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkUpsertControl(ctx, graphutils.NewUpsertRows(input))",
+			save:       "return r.bulkUpsertControl(ctx, graphutils.NewUpsertRows(input))",
+		},
+		{
+			name:       "csv bulk create",
+			field:      "CreateBulkCSVControl",
+			returnType: "ControlBulkCreatePayload",
+			opts:       csvOpts,
+			upload:     true,
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkCreateControl(ctx, inputs)",
+			save:       "return r.bulkCreateControl(ctx, inputs)",
+		},
+		{
+			name:       "csv bulk update",
+			field:      "UpdateBulkCSVControl",
+			returnType: "ControlBulkUpdatePayload",
+			opts:       csvOpts,
+			upload:     true,
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkUpdateCSVControl(ctx, data)",
+			save:       "return r.bulkUpdateCSVControl(ctx, data)",
+		},
+		{
+			name:       "csv bulk upsert",
+			field:      "CreateOrUpdateBulkCSVControl",
+			returnType: "ControlBulkUpsertPayload",
+			opts:       csvOpts,
+			upload:     true,
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkUpsertControl(ctx, rows)",
+			save:       "return r.bulkUpsertControl(ctx, rows)",
+		},
+		{
+			name:       "upload without csv generated package",
+			field:      "CreateBulkCSVControl",
+			returnType: "ControlBulkCreatePayload",
+			upload:     true,
+			dryRunType: ast.NamedType("Boolean", nil),
+			dryRun:     "return r.dryRunBulkCreateControl(ctx, data)",
+			save:       "return r.bulkCreateControl(ctx, data)",
+		},
+		{
+			name:       "bulk create without dry run argument",
+			field:      "CreateBulkControl",
+			returnType: "ControlBulkCreatePayload",
+			save:       "return r.bulkCreateControl(ctx, input)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+
+			field := newCRUDField(tc.field, tc.returnType)
+			if tc.dryRunType != nil {
+				field = withDryRunArgument(field, tc.dryRunType)
+			}
+
+			render := plugin.renderBulk
+			if tc.upload {
+				render = plugin.renderBulkUpload
+			}
+
+			rendered, err := render(field)
+			require.NoError(t, err)
+			require.Contains(t, rendered, tc.save)
+
+			if tc.dryRunType == nil {
+				assert.NotContains(t, rendered, "dryRun")
+
+				return
+			}
+
+			condition := "if dryRun != nil && *dryRun {"
+			if tc.dryRunType.NonNull {
+				condition = "if dryRun {"
+			}
+
+			// the dry run returns before the rows are saved
+			require.Contains(t, rendered, condition)
+			require.Contains(t, rendered, tc.dryRun)
+			assert.Less(t, strings.Index(rendered, tc.dryRun), strings.Index(rendered, tc.save))
+		})
+	}
+}
+
+func TestIgnoresDryRun(t *testing.T) {
+	existing := "return r.bulkCreateControl(ctx, input)"
+
+	testCases := []struct {
+		name     string
+		field    *codegen.Field
+		impl     string
+		expected bool
+	}{
+		{
+			name:     "existing resolver without the dry run",
+			field:    withDryRunArgument(newCRUDField("CreateBulkControl", "ControlBulkCreatePayload"), ast.NamedType("Boolean", nil)),
+			impl:     existing,
+			expected: true,
+		},
+		{
+			name:  "existing resolver with the dry run",
+			field: withDryRunArgument(newCRUDField("CreateBulkControl", "ControlBulkCreatePayload"), ast.NamedType("Boolean", nil)),
+			impl:  "if dryRun != nil && *dryRun {\n\treturn r.dryRunBulkCreateControl(ctx, input)\n}\n\n" + existing,
+		},
+		{
+			name:  "no dry run argument",
+			field: newCRUDField("CreateBulkControl", "ControlBulkCreatePayload"),
+			impl:  existing,
+		},
+		{
+			name:  "not a bulk mutation",
+			field: withDryRunArgument(newCRUDField("CreateControl", "ControlCreatePayload"), ast.NamedType("Boolean", nil)),
+			impl:  existing,
+		},
+		{
+			name: "nil field",
+			impl: existing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().ignoresDryRun(tc.impl, tc.field))
+		})
+	}
+}
+
+func TestImplementKeepsResolverIgnoringDryRun(t *testing.T) {
+	stubReserveImport(t)
+
+	existing := "return r.bulkCreateControl(ctx, input)"
+	field := withDryRunArgument(newCRUDField("CreateBulkControl", "ControlBulkCreatePayload"), ast.NamedType("Boolean", nil))
+
+	t.Run("existing resolver is kept with a warning", func(t *testing.T) {
+		plugin := NewWithOptions()
+
+		assert.Equal(t, existing, plugin.Implement(existing, field))
+		require.Len(t, plugin.Warnings(), 1)
+		assert.Contains(t, plugin.Warnings()[0], "CreateBulkControl: the existing resolver does not use the dryRun argument")
+	})
+
+	t.Run("existing resolver using the dry run", func(t *testing.T) {
+		plugin := NewWithOptions()
+		impl := "if dryRun != nil && *dryRun {\n\treturn r.dryRunBulkCreateControl(ctx, input)\n}\n\n" + existing
+
+		assert.Equal(t, impl, plugin.Implement(impl, field))
+		assert.Empty(t, plugin.Warnings())
+	})
+}
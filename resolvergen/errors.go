@@ -1,9 +1,57 @@
 package resolvergen
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/codegen"
+)
 
 // ErrGraphResolverDirRequired is returned when UpdateWorkflowResolvers is called without a directory
 var ErrGraphResolverDirRequired = errors.New("graphResolverDir is required")
 
 // ErrModuleRootNotFound is returned when the module root cannot be determined from import paths or go.mod
 var ErrModuleRootNotFound = errors.New("unable to determine module root")
+
+// ErrInvalidCRUDDirective is returned when the crud directive on a field has a missing or unknown operation
+var ErrInvalidCRUDDirective = errors.New("invalid crud directive")
+
+// TemplateError is returned when the template for a resolver field fails to parse or execute
+type TemplateError struct {
+	// Object is the name of the object the field belongs to, e.g. Mutation
+	Object string
+	// Field is the name of the field that failed to render
+	Field string
+	// Template is the name of the template that failed to render
+	Template string
+	// Err is the underlying parse or execute error
+	Err error
+}
+
+// Error returns the error message with the field and template that failed
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("render template %s for %s.%s: %v", e.Template, e.Object, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// newTemplateError returns a TemplateError for the field and template
+func newTemplateError(f *codegen.Field, templateName string, err error) *TemplateError {
+	te := &TemplateError{
+		Template: templateName,
+		Err:      err,
+	}
+
+	if f != nil {
+		te.Field = f.GoFieldName
+
+		if f.Object != nil {
+			te.Object = f.Object.Name
+		}
+	}
+
+	return te
+}
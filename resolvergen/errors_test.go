@@ -0,0 +1,79 @@
+package resolvergen
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplateError(t *testing.T) {
+	stubReserveImport(t)
+
+	plugin := NewWithOptions(WithTemplateFS(fstest.MapFS{
+		"create.gotpl": {Data: []byte("{{ .Missing")},
+	}))
+
+	_, err := plugin.renderCreate(newCRUDField("CreateTask", "TaskCreatePayload"))
+	require.Error(t, err)
+
+	var te *TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "mutation", te.Object)
+	assert.Equal(t, "CreateTask", te.Field)
+	assert.Equal(t, "create.gotpl", te.Template)
+	assert.Contains(t, err.Error(), "render template create.gotpl for mutation.CreateTask")
+}
+
+func TestImplementTemplateError(t *testing.T) {
+	stubReserveImport(t)
+
+	plugin := NewWithOptions(WithTemplateFS(fstest.MapFS{
+		"create.gotpl": {Data: []byte("{{ .Missing")},
+		"update.gotpl": {Data: []byte("{{ template \"doesnotexist\" . }}")},
+	}))
+
+	createField := newCRUDField("CreateTask", "TaskCreatePayload")
+	updateField := newCRUDField("UpdateTask", "TaskUpdatePayload")
+	deleteField := newCRUDField("DeleteTask", "TaskDeletePayload")
+
+	// failing fields fall back to the default implementation
+	assert.Equal(t, fmt.Sprintf(defaultImplementation, "CreateTask", "CreateTask"), plugin.Implement("", createField))
+	assert.Equal(t, fmt.Sprintf(defaultImplementation, "UpdateTask", "UpdateTask"), plugin.Implement("", updateField))
+
+	// other fields are still rendered
+	assert.Contains(t, plugin.Implement("", deleteField), "Task.Delete")
+
+	err := plugin.templateError()
+	require.Error(t, err)
+
+	var te *TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "CreateTask", te.Field)
+
+	// all failures are included in the aggregated error
+	assert.Contains(t, err.Error(), "mutation.CreateTask")
+	assert.Contains(t, err.Error(), "mutation.UpdateTask")
+	assert.NotContains(t, err.Error(), "DeleteTask")
+}
+
+func TestRenderWorkflowTemplateError(t *testing.T) {
+	_, err := renderWorkflowTemplate(fstest.MapFS{
+		"workflow.gotpl": {Data: []byte("{{ .HelperName")},
+	}, &workflowResolverTemplate{HelperName: "workflowResolverHasPending"})
+	require.Error(t, err)
+
+	_, err = renderWorkflowTemplate(fstest.MapFS{}, &workflowResolverTemplate{HelperName: "workflowResolverHasPending"})
+	require.Error(t, err)
+}
+
+func TestTemplateErrorUnwrap(t *testing.T) {
+	errUnderlying := errors.New("boom") //nolint:err113
+	err := newTemplateError(newCRUDField("CreateTask", "TaskCreatePayload"), "create.gotpl", errUnderlying)
+
+	assert.ErrorIs(t, err, errUnderlying)
+	assert.Equal(t, "render template create.gotpl for mutation.CreateTask: boom", err.Error())
+}
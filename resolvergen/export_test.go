@@ -0,0 +1,94 @@
+package resolvergen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestCSVExportOperation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		field          string
+		returnType     string
+		expectedOp     string
+		expectedEntity string
+	}{
+		{name: "export", field: "ExportControlCSV", returnType: "String", expectedOp: ExportOperation, expectedEntity: "Control"},
+		{name: "lowercase csv suffix", field: "ExportInternalPolicyCsv", returnType: "String", expectedOp: ExportOperation, expectedEntity: "InternalPolicy"},
+		{name: "export without entity", field: "ExportCSV", returnType: "String", expectedOp: GetOperation, expectedEntity: "String"},
+		{name: "connection", field: "Controls", returnType: "ControlConnection", expectedOp: ListOperation, expectedEntity: "Control"},
+		{name: "export in entity name", field: "Export", returnType: "Export", expectedOp: GetOperation, expectedEntity: "Export"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := newCRUDField(tc.field, tc.returnType)
+
+			assert.Equal(t, tc.expectedOp, New().queryOperation(field))
+			assert.Equal(t, tc.expectedEntity, New().fieldEntityName(field))
+		})
+	}
+}
+
+func TestRenderExport(t *testing.T) {
+	stubReserveImport(t)
+
+	whereArg := &ast.ArgumentDefinition{Name: "where", Type: ast.NamedType("ControlWhereInput", nil)}
+	orderByArg := &ast.ArgumentDefinition{Name: "orderBy", Type: ast.ListType(ast.NonNullNamedType("ControlOrder", nil), nil)}
+
+	testCases := []struct {
+		name        string
+		opts        []Options
+		args        ast.ArgumentDefinitionList
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "where and order by",
+			args: ast.ArgumentDefinitionList{whereArg, orderByArg},
+			contains: []string{
+				"return r.exportControlCSV(",
+				"generated.WithControlOrder(orderBy),",
+				"generated.WithControlFilter(where.Filter),",
+			},
+			notContains: []string{"where.StatusNEQ"},
+		},
+		{
+			name:        "no arguments",
+			contains:    []string{"return r.exportControlCSV(\n\tctx,)"},
+			notContains: []string{"WithControlOrder", "WithControlFilter"},
+		},
+		{
+			name: "archivable schema",
+			opts: []Options{WithArchivableSchemas([]string{"Control"})},
+			args: ast.ArgumentDefinitionList{whereArg},
+			contains: []string{
+				"where.StatusNEQ = &archivedStatus",
+				"generated.WithControlFilter(where.Filter),",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+
+			field := newCRUDField("ExportControlCSV", "String")
+			field.FieldDefinition.Arguments = tc.args
+
+			rendered, err := plugin.renderOperation(field, New().queryOperation(field))
+			require.NoError(t, err)
+
+			for _, s := range tc.contains {
+				assert.Contains(t, rendered, s)
+			}
+
+			for _, s := range tc.notContains {
+				assert.NotContains(t, rendered, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package resolvergen
+
+import (
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// newQueryField returns a query field with the return type and directives
+func newQueryField(goFieldName, returnType string, directives ...*ast.Directive) *codegen.Field {
+	field := newCRUDField(goFieldName, returnType, directives...)
+	field.Object.Definition.Name = string(ast.Query)
+
+	return field
+}
+
+func TestIsBulkJobField(t *testing.T) {
+	testCases := []struct {
+		name           string
+		field          *codegen.Field
+		expected       bool
+		expectedEntity string
+	}{
+		{
+			name:           "csv bulk create job",
+			field:          newCRUDField("CreateBulkCSVControlJob", "BulkJob"),
+			expected:       true,
+			expectedEntity: "Control",
+		},
+		{
+			name:           "csv bulk update job",
+			field:          newCRUDField("UpdateBulkCSVInternalPolicyJob", "BulkJob"),
+			expected:       true,
+			expectedEntity: "InternalPolicy",
+		},
+		{
+			name:           "csv bulk mutation of an object named job",
+			field:          newCRUDField("CreateBulkCSVScheduledJob", "ScheduledJobBulkCreatePayload"),
+			expectedEntity: "ScheduledJob",
+		},
+		{
+			name:           "csv bulk upsert",
+			field:          newCRUDField("CreateOrUpdateBulkCSVControlJob", "BulkJob"),
+			expectedEntity: "Control",
+		},
+		{
+			name:           "bulk job query",
+			field:          newQueryField("BulkJob", "BulkJob"),
+			expectedEntity: "Job",
+		},
+		{
+			name:           "not a bulk mutation",
+			field:          newCRUDField("CreateControlJob", "BulkJob"),
+			expectedEntity: "Job",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, New().isBulkJobField(tc.field))
+			assert.Equal(t, tc.expectedEntity, New().fieldEntityName(tc.field))
+		})
+	}
+}
+
+func TestRenderBulkJobUpload(t *testing.T) {
+	stubReserveImport(t)
+
+	csvOpts := []Options{WithCSVGeneratedPackage("github.com/example/app/internal/ent/csvgenerated")}
+
+	testCases := []struct {
+		name        string
+		field       string
+		opts        []Options
+		contains    string
+		notContains []string
+	}{
+		{
+			name:        "csv bulk create job",
+			field:       "CreateBulkCSVControlJob",
+			opts:        csvOpts,
+			contains:    "return r.enqueueBulkCreateControlJob(ctx, inputs)",
+			notContains: []string{"r.bulkCreateControl(", "dryRun"},
+		},
+		{
+			name:        "csv bulk update job",
+			field:       "UpdateBulkCSVControlJob",
+			opts:        csvOpts,
+			contains:    "return r.enqueueBulkUpdateCSVControlJob(ctx, data)",
+			notContains: []string{"r.bulkUpdateCSVControl(", "dryRun"},
+		},
+		{
+			name:        "upload without csv generated package",
+			field:       "CreateBulkCSVControlJob",
+			contains:    "return r.enqueueBulkCreateControlJob(ctx, data)",
+			notContains: []string{"r.bulkCreateControl(", "dryRun"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+
+			// the job variants ignore the dry run argument, the rows are only validated by the synchronous mutation
+			field := withDryRunArgument(newCRUDField(tc.field, "BulkJob"), ast.NamedType("Boolean", nil))
+
+			rendered, err := plugin.renderBulkUpload(field)
+			require.NoError(t, err)
+			assert.Contains(t, rendered, tc.contains)
+
+			for _, s := range tc.notContains {
+				assert.NotContains(t, rendered, s)
+			}
+
+			assert.False(t, New().ignoresDryRun(rendered, field))
+		})
+	}
+}
+
+func TestRenderBulkJobQuery(t *testing.T) {
+	stubReserveImport(t)
+
+	testCases := []struct {
+		name  string
+		field *codegen.Field
+	}{
+		{
+			name:  "bulk job query",
+			field: newQueryField("BulkJob", "BulkJob"),
+		},
+		{
+			name:  "crud directive",
+			field: newQueryField("ImportStatus", "ImportJob", newCRUDDirective("BULK_JOB", "")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, BulkJobOperation, New().queryOperation(tc.field))
+
+			rendered, err := NewWithOptions().queryImplementer(tc.field)
+			require.NoError(t, err)
+			assert.Contains(t, rendered, "return r.bulkJobRunner().Status(ctx, id)")
+		})
+	}
+}
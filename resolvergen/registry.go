@@ -0,0 +1,84 @@
+package resolvergen
+
+import (
+	"github.com/99designs/gqlgen/codegen"
+)
+
+// TemplateKey identifies an entity and operation pair for a template override
+type TemplateKey struct {
+	// Entity is the schema name of the entity, e.g. Organization
+	Entity string
+	// Operation is the operation type, e.g. CreateOperation
+	Operation string
+}
+
+// FieldTemplate maps resolver fields to a defined template (a `define` block in the child templates)
+// that is used to implement the field instead of the base resolver
+type FieldTemplate struct {
+	// Match returns true when the template should be used for the resolver field
+	Match func(goFieldName string) bool
+	// Template is the name of the defined template, e.g. revisionBump
+	Template string
+}
+
+// FieldNameIs returns a matcher for a FieldTemplate that matches the exact resolver field name
+func FieldNameIs(goFieldName string) func(string) bool {
+	return func(name string) bool {
+		return name == goFieldName
+	}
+}
+
+// defaultFieldTemplates are the custom field templates included with the plugin,
+// the templates are defined in `templates/updatefields/*.gotpl` and `templates/deletefields/*.gotpl`
+var defaultFieldTemplates = []FieldTemplate{
+	{Match: FieldNameIs("RevisionBump"), Template: "revisionBump"},
+	{Match: FieldNameIs("AddComment"), Template: "addcomment"},
+	{Match: isCommentUpdateOnObject, Template: "updatecomment"},
+	{Match: FieldNameIs("DeleteComment"), Template: "deletecomment"},
+}
+
+// defaultOperationTemplates are the embedded templates used to implement each operation
+var defaultOperationTemplates = map[string]string{
+	CreateOperation:    "create.gotpl",
+	UpdateOperation:    "update.gotpl",
+	DeleteOperation:    "delete.gotpl",
+	RestoreOperation:   "restore.gotpl",
+	ArchiveOperation:   "archive.gotpl",
+	UnarchiveOperation: "unarchive.gotpl",
+	BulkOperation:      "bulk.gotpl",
+	BulkCSVOperation:   "upload.gotpl",
+	UploadOperation:    "upload.gotpl",
+	GetOperation:       "get.gotpl",
+	ListOperation:      "list.gotpl",
+	ExportOperation:    "export.gotpl",
+	BulkJobOperation:   "bulkjob.gotpl",
+}
+
+// operationTemplate returns the template to render for the field, using the entity template
+// registered for the entity and operation when set and the default operation template otherwise
+func (r *ResolverPlugin) operationTemplate(field *codegen.Field, operation string) string {
+	if t, ok := r.entityTemplates[TemplateKey{Entity: r.fieldEntityName(field), Operation: operation}]; ok {
+		return t
+	}
+
+	return defaultOperationTemplates[operation]
+}
+
+// fieldTemplate returns the name of the defined template used to implement the resolver field,
+// or an empty string when the base resolver should be used
+func (r *ResolverPlugin) fieldTemplate(goFieldName string) string {
+	registry := r.fieldTemplates
+
+	// the templates included with the plugin are only used when custom fields are enabled
+	if r.includeCustomFields {
+		registry = append(registry[:len(registry):len(registry)], defaultFieldTemplates...)
+	}
+
+	for _, ft := range registry {
+		if ft.Match != nil && ft.Match(goFieldName) {
+			return ft.Template
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,157 @@
+package resolvergen
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubReserveImport replaces the gqlgen import reservation for the duration of the test
+// so templates can be rendered without a full codegen run
+func stubReserveImport(t *testing.T) {
+	t.Helper()
+
+	original := reserveImport
+	reserveImport = func(string, ...string) (string, error) { return "", nil }
+
+	t.Cleanup(func() { reserveImport = original })
+}
+
+func TestFieldTemplate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		opts      []Options
+		fieldName string
+		expected  string
+	}{
+		{
+			name:      "revision bump",
+			fieldName: "RevisionBump",
+			expected:  "revisionBump",
+		},
+		{
+			name:      "add comment",
+			fieldName: "AddComment",
+			expected:  "addcomment",
+		},
+		{
+			name:      "update comment on object",
+			fieldName: "UpdateTaskComment",
+			expected:  "updatecomment",
+		},
+		{
+			name:      "delete comment",
+			fieldName: "DeleteComment",
+			expected:  "deletecomment",
+		},
+		{
+			name:      "base resolver",
+			fieldName: "UpdateTask",
+			expected:  "",
+		},
+		{
+			name:      "custom fields excluded",
+			opts:      []Options{WithExcludeCustomUpdateFields()},
+			fieldName: "RevisionBump",
+			expected:  "",
+		},
+		{
+			name:      "registered template takes precedence",
+			opts:      []Options{WithFieldTemplates(FieldTemplate{Match: FieldNameIs("AddComment"), Template: "addnote"})},
+			fieldName: "AddComment",
+			expected:  "addnote",
+		},
+		{
+			name:      "registered template used when custom fields excluded",
+			opts:      []Options{WithExcludeCustomUpdateFields(), WithFieldTemplates(FieldTemplate{Match: FieldNameIs("UpdateTask"), Template: "updatetask"})},
+			fieldName: "UpdateTask",
+			expected:  "updatetask",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+			assert.Equal(t, tc.expected, plugin.fieldTemplate(tc.fieldName))
+		})
+	}
+}
+
+func TestOperationTemplate(t *testing.T) {
+	plugin := NewWithOptions(WithEntityTemplates(map[TemplateKey]string{
+		{Entity: "Organization", Operation: CreateOperation}: "create_org.gotpl",
+	}))
+
+	assert.Equal(t, "upload.gotpl", plugin.operationTemplate(newCRUDField("CreateBulkCSVTask", "TaskBulkCreatePayload"), BulkCSVOperation))
+	assert.Equal(t, "create_org.gotpl", plugin.operationTemplate(newCRUDField("CreateOrganization", "OrganizationCreatePayload"), CreateOperation))
+	assert.Equal(t, "update.gotpl", plugin.operationTemplate(newCRUDField("UpdateOrganization", "OrganizationUpdatePayload"), UpdateOperation))
+	assert.Equal(t, "create.gotpl", plugin.operationTemplate(newCRUDField("CreateTask", "TaskCreatePayload"), CreateOperation))
+}
+
+func TestRenderUpdateFieldTemplates(t *testing.T) {
+	stubReserveImport(t)
+
+	t.Run("revision bump uses field template", func(t *testing.T) {
+		plugin := New()
+
+		rendered, err := plugin.renderUpdate(newCRUDField("RevisionBump", "String"))
+		require.NoError(t, err)
+
+		assert.Contains(t, rendered, "models.WithVersionBumpRequestContext(ctx, data)")
+		assert.NotContains(t, rendered, "&lt;")
+	})
+
+	t.Run("custom fields excluded uses base resolver", func(t *testing.T) {
+		plugin := NewWithOptions(WithExcludeCustomUpdateFields())
+
+		rendered, err := plugin.renderUpdate(newCRUDField("RevisionBump", "String"))
+		require.NoError(t, err)
+
+		assert.NotContains(t, rendered, "WithVersionBumpRequestContext")
+		assert.Contains(t, rendered, "res.Update().SetInput(input)")
+	})
+
+	t.Run("registered field template from template fs", func(t *testing.T) {
+		plugin := NewWithOptions(
+			WithTemplateFS(fstest.MapFS{
+				"updatefields/archive.gotpl": {Data: []byte(`{{ define "archivetask" }}return r.archiveTask(ctx, id){{ end }}`)},
+			}),
+			WithFieldTemplates(FieldTemplate{Match: FieldNameIs("UpdateTask"), Template: "archivetask"}),
+		)
+
+		rendered, err := plugin.renderUpdate(newCRUDField("UpdateTask", "TaskUpdatePayload"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "return r.archiveTask(ctx, id)", rendered)
+	})
+
+	t.Run("missing field template falls back to base resolver", func(t *testing.T) {
+		plugin := NewWithOptions(WithFieldTemplates(FieldTemplate{Match: FieldNameIs("UpdateTask"), Template: "doesnotexist"}))
+
+		rendered, err := plugin.renderUpdate(newCRUDField("UpdateTask", "TaskUpdatePayload"))
+		require.NoError(t, err)
+
+		assert.Contains(t, rendered, "withTransactionalMutation(ctx).Task.Get(ctx, id)")
+	})
+
+	t.Run("entity template", func(t *testing.T) {
+		plugin := NewWithOptions(
+			WithTemplateFS(fstest.MapFS{
+				"create_org.gotpl": {Data: []byte(`return r.createOrganization(ctx, input)`)},
+			}),
+			WithEntityTemplates(map[TemplateKey]string{
+				{Entity: "Organization", Operation: CreateOperation}: "create_org.gotpl",
+			}),
+		)
+
+		rendered, err := plugin.renderCreate(newCRUDField("CreateOrganization", "OrganizationCreatePayload"))
+		require.NoError(t, err)
+		assert.Equal(t, "return r.createOrganization(ctx, input)", rendered)
+
+		rendered, err = plugin.renderCreate(newCRUDField("CreateTask", "TaskCreatePayload"))
+		require.NoError(t, err)
+		assert.Contains(t, rendered, "withTransactionalMutation(ctx).Task.Create()")
+	})
+}
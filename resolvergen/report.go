@@ -0,0 +1,68 @@
+package resolvergen
+
+import (
+	"github.com/99designs/gqlgen/codegen"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+)
+
+// reportResolvers adds the classification of every resolver field to the dry run report
+// without rendering any templates
+func (r *ResolverPlugin) reportResolvers(data *codegen.Data) {
+	for _, o := range data.Objects {
+		for _, f := range o.Fields {
+			if !f.IsResolver {
+				continue
+			}
+
+			r.dryRunReport.AddResolver(r.resolverReport(f))
+		}
+	}
+}
+
+// resolverReport returns the report entry for the resolver field with the operation and templates
+// that would be used to implement it, fields that are not implemented by the plugin have no operation
+func (r *ResolverPlugin) resolverReport(f *codegen.Field) genreport.Resolver {
+	entry := genreport.Resolver{
+		Object: f.Object.Name,
+		Field:  f.GoFieldName,
+	}
+
+	switch {
+	case isMutation(f), isInput(f), isQuery(f):
+		r.checkOperationClassification(f)
+
+		if isQuery(f) {
+			entry.Operation = r.queryOperation(f)
+		} else {
+			entry.Operation = r.mutationOperation(f)
+		}
+	case isWorkflowResolverField(f):
+		entry.Template = workflowTemplate
+
+		return entry
+	default:
+		return entry
+	}
+
+	if !r.implementsOperation(r.fieldEntityName(f), entry.Operation) {
+		entry.Operation = ""
+	}
+
+	if entry.Operation == "" {
+		return entry
+	}
+
+	if d, err := getCRUDDirective(f); err == nil && d != nil {
+		entry.Directive = true
+	}
+
+	entry.Entity = r.fieldEntityName(f)
+	entry.Template = r.operationTemplate(f, entry.Operation)
+
+	if entry.Operation == UpdateOperation || entry.Operation == DeleteOperation {
+		entry.FieldTemplate = r.fieldTemplate(f.GoFieldName)
+	}
+
+	return entry
+}
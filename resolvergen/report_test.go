@@ -0,0 +1,117 @@
+package resolvergen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/99designs/gqlgen/codegen"
+	"github.com/99designs/gqlgen/codegen/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+)
+
+func TestResolverReport(t *testing.T) {
+	queryField := newCRUDField("Tasks", "TaskConnection")
+	queryField.Object = &codegen.Object{Definition: &ast.Definition{Name: string(ast.Query)}}
+
+	scalarField := newCRUDField("RevisionBump", "String")
+	scalarField.TypeReference.Definition.Kind = ast.Scalar
+
+	testCases := []struct {
+		name     string
+		opts     []Options
+		field    *codegen.Field
+		expected genreport.Resolver
+	}{
+		{
+			name:  "create",
+			field: newCRUDField("CreateTask", "TaskCreatePayload"),
+			expected: genreport.Resolver{
+				Object: "mutation", Field: "CreateTask", Entity: "Task", Operation: CreateOperation, Template: "create.gotpl",
+			},
+		},
+		{
+			name:  "update with field template",
+			field: scalarField,
+			expected: genreport.Resolver{
+				Object: "mutation", Field: "RevisionBump", Entity: "String", Operation: UpdateOperation, Template: "update.gotpl", FieldTemplate: "revisionBump",
+			},
+		},
+		{
+			name:  "directive",
+			field: newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload", newCRUDDirective("CREATE", "DeleteRequest")),
+			expected: genreport.Resolver{
+				Object: "mutation", Field: "CreateDeleteRequest", Entity: "DeleteRequest", Operation: CreateOperation, Template: "create.gotpl", Directive: true,
+			},
+		},
+		{
+			name: "entity template",
+			opts: []Options{WithEntityTemplates(map[TemplateKey]string{
+				{Entity: "Task", Operation: CreateOperation}: "create_task.gotpl",
+			})},
+			field: newCRUDField("CreateTask", "TaskCreatePayload"),
+			expected: genreport.Resolver{
+				Object: "mutation", Field: "CreateTask", Entity: "Task", Operation: CreateOperation, Template: "create_task.gotpl",
+			},
+		},
+		{
+			name:  "list query",
+			field: queryField,
+			expected: genreport.Resolver{
+				Object: "query", Field: "Tasks", Entity: "Task", Operation: ListOperation, Template: "list.gotpl",
+			},
+		},
+		{
+			name:     "not implemented",
+			field:    newCRUDField("Login", "LoginPayload"),
+			expected: genreport.Resolver{Object: "mutation", Field: "Login"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+			assert.Equal(t, tc.expected, plugin.resolverReport(tc.field))
+		})
+	}
+}
+
+func TestGenerateCodeDryRun(t *testing.T) {
+	report := genreport.New()
+	plugin := NewWithOptions(WithDryRun(report))
+
+	createField := newCRUDField("CreateTask", "TaskCreatePayload")
+	createField.IsResolver = true
+
+	ambiguousField := newCRUDField("CreateDeleteRequest", "DeleteRequestCreatePayload")
+	ambiguousField.IsResolver = true
+
+	nonResolverField := newCRUDField("ID", "ID")
+
+	resolverDir := t.TempDir()
+
+	data := &codegen.Data{
+		Config: &config.Config{
+			Resolver: config.ResolverConfig{Package: "graphapi", Layout: config.LayoutFollowSchema, DirName: resolverDir},
+			Model:    config.PackageConfig{Package: "graphapi"},
+		},
+		Objects: codegen.Objects{
+			{Definition: &ast.Definition{Name: "Mutation"}, Fields: []*codegen.Field{createField, ambiguousField, nonResolverField}},
+		},
+	}
+
+	require.NoError(t, plugin.GenerateCode(data))
+
+	require.Len(t, report.Resolvers, 2)
+	assert.Equal(t, "CreateTask", report.Resolvers[0].Field)
+	assert.Equal(t, "create.gotpl", report.Resolvers[0].Template)
+	assert.Len(t, report.Warnings, 1)
+
+	// nothing is written to the resolver directory
+	entries, err := os.ReadDir(resolverDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -1,15 +1,23 @@
 package resolvergen
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"strings"
 
 	"github.com/99designs/gqlgen/codegen"
 	"github.com/99designs/gqlgen/plugin"
 	"github.com/99designs/gqlgen/plugin/resolvergen"
+	"github.com/rs/zerolog/log"
 	"github.com/vektah/gqlparser/v2/ast"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+
+	"github.com/theopenlane/gqlgen-plugins/bulkgen"
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 var (
@@ -39,8 +47,34 @@ type ResolverPlugin struct {
 	// implementations with freshly generated code from templates. Use this for one-time
 	// migrations when bulk templates change, then disable to preserve custom logic.
 	forceRegenerateBulkResolvers bool
+	// mutationNames matches the names of the bulk and CSV bulk mutations, using the same patterns as bulkgen
+	mutationNames *bulkgen.MutationNameMatcher
+	// mutationNamesErr is the error parsing the mutation name patterns, returned from GenerateCode
+	mutationNamesErr error
 
 	archivableSchemas map[string]bool
+	// optimisticConcurrency checks the expectedUpdatedAt or version field of update inputs against the stored object
+	optimisticConcurrency bool
+	// softDeleteSchemas are the schemas that are soft deleted instead of removed by the delete resolver
+	softDeleteSchemas map[string]bool
+
+	// runtimeImports are the import paths of the runtime packages referenced by the generated code
+	runtimeImports runtimeimports.Config
+
+	// templateOverrides is a filesystem with templates that replace the embedded templates of the same name
+	templateOverrides fs.FS
+	// entityTemplates maps entity and operation pairs to the template used instead of the operation template
+	entityTemplates map[TemplateKey]string
+	// fieldTemplates are the user registered templates for custom update and delete resolver fields
+	fieldTemplates []FieldTemplate
+
+	// dryRunReport when set records the classification of each resolver field instead of generating the resolvers
+	dryRunReport *genreport.Report
+
+	// templateErrors collects the template failures for each field, returned from GenerateCode
+	templateErrors []error
+	// warnings collects the generation warnings, such as fields that were classified using an ambiguous name
+	warnings []string
 }
 
 // Name returns the name of the plugin
@@ -51,8 +85,12 @@ func (r ResolverPlugin) Name() string {
 
 // New returns a new resolver plugin
 func New() *ResolverPlugin {
+	mutationNames, err := bulkgen.NewMutationNameMatcher()
+
 	return &ResolverPlugin{
 		includeCustomFields: true,
+		mutationNames:       mutationNames,
+		mutationNamesErr:    err,
 	}
 }
 
@@ -91,7 +129,8 @@ func WithExcludeCustomUpdateFields() Options {
 	}
 }
 
-// WithArchivableSchemas sets schemas that can have a status of archived
+// WithArchivableSchemas sets schemas that can have a status of archived, the schemas must have an optional
+// and nillable previous_status field of the status enum to keep the status restored by unarchive
 func WithArchivableSchemas(schemas []string) Options {
 	return func(p *ResolverPlugin) {
 		p.archivableSchemas = map[string]bool{}
@@ -102,6 +141,28 @@ func WithArchivableSchemas(schemas []string) Options {
 	}
 }
 
+// WithSoftDeleteSchemas sets schemas that are soft deleted by the delete resolver instead of being removed,
+// the deleted_at time is set (and the status is archived for archivable schemas) and edges are not cleaned up.
+// A restore<Entity> mutation is implemented for these schemas when present in the graphql schema
+func WithSoftDeleteSchemas(schemas []string) Options {
+	return func(p *ResolverPlugin) {
+		p.softDeleteSchemas = map[string]bool{}
+
+		for _, s := range schemas {
+			p.softDeleteSchemas[cases.Title(language.English, cases.Compact).String(s)] = true
+		}
+	}
+}
+
+// WithOptimisticConcurrency enables conflict detection in the update resolvers, when the update input has an
+// expectedUpdatedAt or version field the value is a condition of the update and a graphutils.ConflictError
+// is returned when no object matched because it was updated since it was read. The version is incremented by each update
+func WithOptimisticConcurrency() Options {
+	return func(p *ResolverPlugin) {
+		p.optimisticConcurrency = true
+	}
+}
+
 // WithCSVGeneratedPackage sets the import path for the csvgenerated package
 func WithCSVGeneratedPackage(pkg string) Options {
 	return func(p *ResolverPlugin) {
@@ -118,45 +179,166 @@ func WithForceRegenerateBulkResolvers(enabled bool) Options {
 	}
 }
 
+// WithMutationNamePatterns sets the names of the bulk and CSV bulk mutations, replacing the
+// bulkgen.DefaultMutationNamePatterns. Use the same patterns as bulkgen.WithMutationNamePatterns so the
+// mutations bulkgen generates the bulk functions for are implemented with the bulk templates
+func WithMutationNamePatterns(patterns ...string) Options {
+	return func(p *ResolverPlugin) {
+		p.mutationNames, p.mutationNamesErr = bulkgen.NewMutationNameMatcher(patterns...)
+	}
+}
+
+// WithRuntimeImports sets the import paths of the runtime packages referenced by the generated code,
+// such as the logger and error helpers, empty paths use the defaults of the openlane core layout
+func WithRuntimeImports(imports runtimeimports.Config) Options {
+	return func(p *ResolverPlugin) {
+		p.runtimeImports = imports
+	}
+}
+
+// WithTemplateDir sets a directory with templates that override the embedded templates of the same name,
+// e.g. a directory with only `list.gotpl` or `updatefields/updateresolver.gotpl` replaces just those templates
+func WithTemplateDir(path string) Options {
+	return func(p *ResolverPlugin) {
+		p.templateOverrides = os.DirFS(path)
+	}
+}
+
+// WithTemplateFS sets a filesystem with templates that override the embedded templates of the same name,
+// the paths in the filesystem are relative to the templates directory, e.g. `list.gotpl`
+func WithTemplateFS(fsys fs.FS) Options {
+	return func(p *ResolverPlugin) {
+		p.templateOverrides = fsys
+	}
+}
+
+// WithEntityTemplates sets the templates used for specific entity and operation pairs instead of
+// the default operation template, e.g. {Entity: "Organization", Operation: CreateOperation} to "create_org.gotpl".
+// The templates are parsed with the same child templates as the operation and are usually provided
+// with WithTemplateDir or WithTemplateFS
+func WithEntityTemplates(entityTemplates map[TemplateKey]string) Options {
+	return func(p *ResolverPlugin) {
+		if p.entityTemplates == nil {
+			p.entityTemplates = map[TemplateKey]string{}
+		}
+
+		for k, v := range entityTemplates {
+			p.entityTemplates[k] = v
+		}
+	}
+}
+
+// WithFieldTemplates registers additional field templates for update and delete resolvers,
+// these are checked in order before the custom field templates included with the plugin
+func WithFieldTemplates(fieldTemplates ...FieldTemplate) Options {
+	return func(p *ResolverPlugin) {
+		p.fieldTemplates = append(p.fieldTemplates, fieldTemplates...)
+	}
+}
+
 // Implement gqlgen api.ResolverImplementer
 func (r *ResolverPlugin) Implement(s string, f *codegen.Field) (val string) {
 	// if the field has a custom resolver, use it
 	// panic is not a custom resolver so attempt to implement the field
 	// regenerate bulk operations if forceRegenerateBulkResolvers is enabled
 	if s != "" && !strings.Contains(s, "panic") && !r.shouldRegenerateBulkResolver(f) {
+		r.checkDryRunUsage(s, f)
+
 		return s
 	}
 
+	var (
+		impl string
+		err  error
+	)
+
 	switch {
 	case isMutation(f), isInput(f):
-		return r.mutationImplementer(f)
+		r.checkOperationClassification(f)
+
+		impl, err = r.mutationImplementer(f)
 	case isQuery(f):
-		return r.queryImplementer(f)
+		r.checkOperationClassification(f)
+
+		impl, err = r.queryImplementer(f)
 	case isWorkflowResolverField(f):
-		return r.workflowImplementer(f)
+		impl, err = r.workflowImplementer(f)
 	default:
 		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name)
 	}
+
+	// keep generating the remaining resolvers and report all template failures from GenerateCode
+	if err != nil {
+		r.templateErrors = append(r.templateErrors, err)
+
+		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name)
+	}
+
+	return impl
+}
+
+// WithDryRun records the operation and template each resolver field is classified into in the report
+// instead of generating the resolvers, no resolver files are written
+func WithDryRun(report *genreport.Report) Options {
+	return func(p *ResolverPlugin) {
+		p.dryRunReport = report
+	}
 }
 
 // GenerateCode implements api.CodeGenerator
 func (r *ResolverPlugin) GenerateCode(data *codegen.Data) error {
+	if r.mutationNamesErr != nil {
+		return r.mutationNamesErr
+	}
+
 	// set the model package if it is different from the resolver package
 	if data.Config.Resolver.Package != data.Config.Model.Package {
 		r.modelPackage = data.Config.Model.Package
 	}
 
+	if r.dryRunReport != nil {
+		r.reportResolvers(data)
+		r.dryRunReport.AddWarning(r.warnings...)
+		r.logWarnings()
+
+		return nil
+	}
+
 	// use the default resolver plugin to generate the code
 	if err := r.Plugin.GenerateCode(data); err != nil {
 		return err
 	}
 
+	r.logWarnings()
+
 	resolverDir := data.Config.Resolver.Dir()
 	if resolverDir == "" {
-		return nil
+		return r.templateError()
+	}
+
+	if err := updateWorkflowResolvers(resolverDir, workflowImportPaths{
+		generated:   r.entGeneratedPackage,
+		graphCommon: r.graphqlImport,
+		enums:       r.runtimeImports.Enums,
+		workflows:   r.runtimeImports.Workflows,
+	}); err != nil {
+		return err
+	}
+
+	return r.templateError()
+}
+
+// logWarnings logs the warnings collected during generation
+func (r *ResolverPlugin) logWarnings() {
+	for _, warning := range r.warnings {
+		log.Warn().Msg(warning)
 	}
+}
 
-	return UpdateWorkflowResolvers(resolverDir)
+// templateError returns the template failures collected while implementing the resolvers as a single error,
+// the failing fields are generated with the default not implemented body
+func (r *ResolverPlugin) templateError() error {
+	return errors.Join(r.templateErrors...)
 }
 
 // isMutation returns true if the field is a mutation
@@ -189,42 +371,143 @@ func isWorkflowResolverField(f *codegen.Field) bool {
 }
 
 // mutationImplementer returns the implementation for the mutation
-func (r *ResolverPlugin) mutationImplementer(f *codegen.Field) string {
-	switch crudType(f) {
-	case BulkCSVOperation:
+func (r *ResolverPlugin) mutationImplementer(f *codegen.Field) (string, error) {
+	return r.renderOperation(f, r.mutationOperation(f))
+}
+
+// queryImplementer returns the implementation for the query
+func (r *ResolverPlugin) queryImplementer(f *codegen.Field) (string, error) {
+	return r.renderOperation(f, r.queryOperation(f))
+}
+
+// renderOperation renders the template for the operation, unknown operations
+// use the default not implemented body
+func (r *ResolverPlugin) renderOperation(f *codegen.Field, operation string) (string, error) {
+	if !r.implementsOperation(r.fieldEntityName(f), operation) {
+		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name), nil
+	}
+
+	switch operation {
+	case BulkCSVOperation, UploadOperation:
 		return r.renderBulkUpload(f)
 	case BulkOperation:
 		return r.renderBulk(f)
-	case UploadOperation:
-		return r.renderBulkUpload(f)
 	case CreateOperation:
 		return r.renderCreate(f)
 	case UpdateOperation:
 		return r.renderUpdate(f)
 	case DeleteOperation:
 		return r.renderDelete(f)
+	case RestoreOperation:
+		return r.renderRestore(f)
+	case ArchiveOperation, UnarchiveOperation:
+		return r.renderArchive(f, operation)
+	case GetOperation:
+		return r.renderQuery(f)
+	case ListOperation:
+		return r.renderList(f)
+	case ExportOperation:
+		return r.renderExport(f)
+	case BulkJobOperation:
+		return r.renderBulkJob(f)
+	default:
+		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name), nil
+	}
+}
+
+// implementsOperation returns false for the lifecycle operations of schemas that were not configured for them,
+// restore is only implemented for soft deleted schemas and archive and unarchive for archivable schemas
+func (r *ResolverPlugin) implementsOperation(entity, operation string) bool {
+	switch operation {
+	case RestoreOperation:
+		return r.softDeleteSchemas[entity]
+	case ArchiveOperation, UnarchiveOperation:
+		return r.archivableSchemas[entity]
+	default:
+		return true
+	}
+}
+
+// mutationOperation returns the operation used to implement the mutation field,
+// or an empty string when the field is not a CRUD operation
+func (r *ResolverPlugin) mutationOperation(f *codegen.Field) string {
+	switch op := r.crudType(f); op {
 	case InputObject:
 		// this is needed to handle input fields that are not CRUD operations
 		// first case is RevisionBump - might need to extend for others later
-		return r.renderUpdate(f)
+		return UpdateOperation
+	case BulkCSVOperation, BulkOperation, UploadOperation, CreateOperation,
+		UpdateOperation, DeleteOperation, RestoreOperation, ArchiveOperation, UnarchiveOperation,
+		GetOperation, ListOperation:
+		return op
 	default:
-		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name)
+		return ""
 	}
 }
 
-// queryImplementer returns the implementation for the query
-func (r *ResolverPlugin) queryImplementer(f *codegen.Field) string {
+// queryOperation returns the operation used to implement the query field, CSV export queries are exported,
+// connections are listed and everything else is a get unless set with the crud directive
+func (r *ResolverPlugin) queryOperation(f *codegen.Field) string {
+	if d, err := getCRUDDirective(f); err == nil && d != nil {
+		switch d.Operation {
+		case ListOperation, GetOperation, ExportOperation, BulkJobOperation:
+			return d.Operation
+		default:
+			return r.mutationOperation(f)
+		}
+	}
+
+	if isCSVExportField(f.GoFieldName) {
+		return ExportOperation
+	}
+
+	if f.TypeReference.Definition.Name == BulkJobOperation {
+		return BulkJobOperation
+	}
+
 	if strings.Contains(f.TypeReference.Definition.Name, Connection) {
-		return r.renderList(f)
+		return ListOperation
+	}
+
+	return GetOperation
+}
+
+// isBulkJobField returns true for the job variants of the CSV bulk create and update mutations, e.g.
+// createBulkCSVControlJob, they return a bulk job and enqueue the rows with the job functions generated by bulkgen
+func (r *ResolverPlugin) isBulkJobField(f *codegen.Field) bool {
+	if f == nil || f.Object == nil || f.TypeReference == nil || f.TypeReference.Definition == nil || !isMutation(f) {
+		return false
+	}
+
+	if f.TypeReference.Definition.Name != BulkJobOperation || !strings.HasSuffix(f.GoFieldName, bulkJobSuffix) {
+		return false
+	}
+
+	// the bulk upsert is not run as a job
+	if strings.Contains(f.GoFieldName, CreateOrUpdateOperation) || strings.Contains(f.GoFieldName, UpsertOperation) {
+		return false
+	}
+
+	op := r.crudType(f)
+
+	return op == BulkCSVOperation || op == UploadOperation
+}
+
+// isCSVExportField returns true for the CSV export queries implemented with the export functions
+// generated by bulkgen, e.g. ExportControlCSV, the prefix and suffix are matched case-insensitively
+func isCSVExportField(goFieldName string) bool {
+	if len(goFieldName) <= len(ExportOperation)+len(CSVOperation) {
+		return false
 	}
 
-	return r.renderQuery(f)
+	return strings.EqualFold(goFieldName[:len(ExportOperation)], ExportOperation) &&
+		strings.EqualFold(goFieldName[len(goFieldName)-len(CSVOperation):], CSVOperation)
 }
 
-func (r *ResolverPlugin) workflowImplementer(f *codegen.Field) string {
+func (r *ResolverPlugin) workflowImplementer(f *codegen.Field) (string, error) {
 	helperName, ok := workflowResolverHelpers[f.GoFieldName]
 	if !ok {
-		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name)
+		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name), nil
 	}
 
 	objectType := ""
@@ -233,15 +516,20 @@ func (r *ResolverPlugin) workflowImplementer(f *codegen.Field) string {
 	}
 
 	if objectType == "" {
-		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name)
+		return fmt.Sprintf(defaultImplementation, f.GoFieldName, f.Name), nil
 	}
 
-	return renderWorkflowTemplate(&workflowResolverTemplate{
+	impl, err := renderWorkflowTemplate(r.templateFS(), &workflowResolverTemplate{
 		HelperName: helperName,
 		ObjectType: objectType,
 		EntPackage: getEntPackageFromImport(r.entGeneratedPackage),
 		IsTimeline: helperName == "workflowResolverTimeline",
 	})
+	if err != nil {
+		return "", newTemplateError(f, workflowTemplate, err)
+	}
+
+	return impl, nil
 }
 
 // shouldRegenerateBulkResolver returns true if the resolver should be forcefully regenerated.
@@ -254,18 +542,114 @@ func (r *ResolverPlugin) shouldRegenerateBulkResolver(f *codegen.Field) bool {
 
 	// Only regenerate actual bulk mutation resolvers, not extended resolvers or other fields
 	// that happen to contain "Bulk" or "CSV" in their names
-	fieldName := f.GoFieldName
+	return r.isBulkMutationResolver(f)
+}
 
-	// Check for specific bulk mutation patterns (createBulk*, updateBulk*, deleteBulk*)
-	isBulkMutation := strings.HasPrefix(fieldName, "CreateBulk") ||
-		strings.HasPrefix(fieldName, "UpdateBulk") ||
-		strings.HasPrefix(fieldName, "DeleteBulk")
+// isBulkMutationResolver returns true for the mutations matching the mutation name patterns,
+// e.g. createBulkControl or updateBulkCSVControl with the default patterns
+func (r *ResolverPlugin) isBulkMutationResolver(f *codegen.Field) bool {
+	_, ok, _ := r.bulkMutationName(f)
 
-	return isBulkMutation
+	return ok
+}
+
+// bulkMutationName returns the bulk or CSV bulk mutation of the field matched by the mutation name patterns,
+// the job variants of the CSV bulk mutations are matched without their Job suffix. A name matching more than
+// one pattern is not matched and returns the error, which is reported by checkOperationClassification
+func (r *ResolverPlugin) bulkMutationName(f *codegen.Field) (bulkgen.MutationName, bool, error) {
+	if f == nil || f.Object == nil || r.mutationNames == nil || !isMutation(f) {
+		return bulkgen.MutationName{}, false, nil
+	}
+
+	name := f.Name
+	if f.TypeReference != nil && f.TypeReference.Definition != nil && f.TypeReference.Definition.Name == BulkJobOperation {
+		name = strings.TrimSuffix(name, bulkJobSuffix)
+	}
+
+	return r.mutationNames.Match(name)
+}
+
+// checkDryRunUsage records a warning when the existing resolver of a bulk mutation ignores its dryRun argument,
+// the resolver is kept so custom logic is not overwritten and must be updated or regenerated with
+// WithForceRegenerateBulkResolvers, otherwise a dry run saves the rows
+func (r *ResolverPlugin) checkDryRunUsage(impl string, f *codegen.Field) {
+	if !r.ignoresDryRun(impl, f) {
+		return
+	}
+
+	r.warnings = append(r.warnings, fmt.Sprintf("%s.%s: the existing resolver does not use the dryRun argument so a dry run saves the rows, "+
+		"handle dryRun in the resolver or regenerate it with WithForceRegenerateBulkResolvers", f.Object.Name, f.Name))
+}
+
+// ignoresDryRun returns true when a bulk mutation has a dryRun argument that is not used by its existing
+// resolver, e.g. the argument was added to the schema after the resolver was generated
+func (r *ResolverPlugin) ignoresDryRun(impl string, f *codegen.Field) bool {
+	// the job variants enqueue the rows and do not have a dry run
+	if f == nil || f.FieldDefinition == nil || !r.isBulkMutationResolver(f) || r.isBulkJobField(f) {
+		return false
+	}
+
+	return hasArgument("dryRun", f.FieldDefinition.Arguments) && !strings.Contains(impl, "dryRun")
+}
+
+// Warnings returns the warnings collected while implementing the resolvers
+func (r *ResolverPlugin) Warnings() []string {
+	return r.warnings
 }
 
-// crudType returns the type of CRUD operation
-func crudType(f *codegen.Field) string {
+// checkOperationClassification records a warning when the crud directive on the field is invalid
+// or when the field has no directive and its name is ambiguous for the name based classification
+func (r *ResolverPlugin) checkOperationClassification(f *codegen.Field) {
+	d, err := getCRUDDirective(f)
+	if err != nil {
+		r.warnings = append(r.warnings, fmt.Sprintf("%s.%s: %v, falling back to the field name", f.Object.Name, f.Name, err))
+
+		return
+	}
+
+	if d != nil || isQuery(f) {
+		return
+	}
+
+	if _, _, err := r.bulkMutationName(f); err != nil {
+		r.warnings = append(r.warnings, fmt.Sprintf("%s.%s: %v, falling back to the field name", f.Object.Name, f.Name, err))
+
+		return
+	}
+
+	if isAmbiguousOperationName(f.GoFieldName) {
+		r.warnings = append(r.warnings, fmt.Sprintf("%s.%s: ambiguous field name classified as %q, add the @%s directive to set the operation explicitly",
+			f.Object.Name, f.Name, r.crudType(f), CRUDDirective))
+	}
+}
+
+// crudType returns the type of CRUD operation, using the crud directive when set on the field
+// and falling back to the field name
+func (r *ResolverPlugin) crudType(f *codegen.Field) string {
+	if d, err := getCRUDDirective(f); err == nil && d != nil {
+		return d.Operation
+	}
+
+	switch {
+	// lifecycle operations are matched on the prefix only, restore<Entity> mutations are generated for soft deleted
+	// schemas and (un)archive<Entity> and (un)archiveBulk<Entity> mutations for archivable schemas
+	case strings.HasPrefix(f.GoFieldName, RestoreOperation):
+		return RestoreOperation
+	case strings.HasPrefix(f.GoFieldName, UnarchiveOperation):
+		return UnarchiveOperation
+	case strings.HasPrefix(f.GoFieldName, ArchiveOperation):
+		return ArchiveOperation
+	}
+
+	// the bulk mutations are matched with the mutation name patterns, e.g. {op}Many{Entity}
+	if matched, ok, _ := r.bulkMutationName(f); ok {
+		if matched.CSV {
+			return BulkCSVOperation
+		}
+
+		return BulkOperation
+	}
+
 	switch {
 	case strings.Contains(f.GoFieldName, CSVOperation):
 		return BulkCSVOperation
@@ -1,10 +1,13 @@
 package resolvergen
 
 import (
+	"strings"
 	"testing"
 
-	"github.com/99designs/gqlgen/codegen"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 func TestWithCSVGeneratedPackage(t *testing.T) {
@@ -89,6 +92,12 @@ func TestShouldRegenerateBulkResolver(t *testing.T) {
 			forceEnabled:  true,
 			expectedRegen: true,
 		},
+		{
+			name:          "BulkCreate with force enabled",
+			fieldName:     "BulkCreateControl",
+			forceEnabled:  true,
+			expectedRegen: true,
+		},
 		{
 			name:          "CreateBulk with force disabled",
 			fieldName:     "CreateBulkControl",
@@ -125,11 +134,7 @@ func TestShouldRegenerateBulkResolver(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			plugin := NewWithOptions(WithForceRegenerateBulkResolvers(tc.forceEnabled))
 
-			field := &codegen.Field{
-				GoFieldName: tc.fieldName,
-			}
-
-			result := plugin.shouldRegenerateBulkResolver(field)
+			result := plugin.shouldRegenerateBulkResolver(newCRUDField(tc.fieldName, "Payload"))
 			assert.Equal(t, tc.expectedRegen, result)
 		})
 	}
@@ -169,3 +174,30 @@ func TestPluginName(t *testing.T) {
 	plugin := New()
 	assert.Equal(t, "resolvergen", plugin.Name())
 }
+
+func TestWithRuntimeImports(t *testing.T) {
+	reserved := map[string]string{}
+
+	original := reserveImport
+	reserveImport = func(path string, aliases ...string) (string, error) {
+		reserved[path] = strings.Join(aliases, "")
+
+		return "", nil
+	}
+
+	t.Cleanup(func() { reserveImport = original })
+
+	plugin := NewWithOptions(WithRuntimeImports(runtimeimports.Config{
+		Logger: "github.com/example/app/pkg/log",
+		Errors: "github.com/example/app/pkg/errors",
+	}))
+
+	rendered, err := plugin.renderCreate(newCRUDField("CreateTask", "TaskCreatePayload"))
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "withTransactionalMutation(ctx).Task.Create()")
+
+	assert.Equal(t, "logx", reserved["github.com/example/app/pkg/log"])
+	assert.Equal(t, "rout", reserved["github.com/example/app/pkg/errors"])
+	assert.NotContains(t, reserved, runtimeimports.DefaultLogger)
+	assert.NotContains(t, reserved, runtimeimports.DefaultErrors)
+}
@@ -0,0 +1,187 @@
+package resolvergen
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestWithSoftDeleteSchemas(t *testing.T) {
+	plugin := NewWithOptions(WithSoftDeleteSchemas([]string{"control", "policy"}))
+
+	assert.True(t, plugin.softDeleteSchemas["Control"])
+	assert.True(t, plugin.softDeleteSchemas["Policy"])
+	assert.False(t, plugin.softDeleteSchemas["Task"])
+}
+
+func TestRenderDeleteSoftDelete(t *testing.T) {
+	stubReserveImport(t)
+
+	testCases := []struct {
+		name        string
+		opts        []Options
+		field       string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "hard delete",
+			field:       "DeleteTask",
+			contains:    []string{"Task.DeleteOneID(id).Exec(ctx)", "generated.TaskEdgeCleanup(ctx, id)"},
+			notContains: []string{"SetDeletedAt"},
+		},
+		{
+			name:        "soft delete",
+			opts:        []Options{WithSoftDeleteSchemas([]string{"Task"})},
+			field:       "DeleteTask",
+			contains:    []string{"Task.UpdateOneID(id).", "SetDeletedAt(time.Now())."},
+			notContains: []string{"DeleteOneID", "EdgeCleanup", "SetStatus"},
+		},
+		{
+			name:  "soft delete archivable schema",
+			opts:  []Options{WithSoftDeleteSchemas([]string{"Control"}), WithArchivableSchemas([]string{"Control"})},
+			field: "DeleteControl",
+			contains: []string{
+				"current, err := c.Control.Get(ctx, id)",
+				"Where(control.StatusEQ(current.Status)).",
+				"SetDeletedAt(time.Now())",
+				"if current.Status != enums.ControlStatusArchived {",
+				"update.SetPreviousStatus(current.Status).SetStatus(enums.ControlStatusArchived)",
+			},
+			notContains: []string{"DeleteOneID", "EdgeCleanup"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+
+			rendered, err := plugin.renderDelete(newCRUDField(tc.field, tc.field[len(DeleteOperation):]+"DeletePayload"))
+			require.NoError(t, err)
+
+			for _, c := range tc.contains {
+				assert.Contains(t, rendered, c)
+			}
+
+			for _, c := range tc.notContains {
+				assert.NotContains(t, rendered, c)
+			}
+		})
+	}
+}
+
+func TestRenderRestore(t *testing.T) {
+	stubReserveImport(t)
+
+	t.Run("soft deleted schema", func(t *testing.T) {
+		plugin := NewWithOptions(WithSoftDeleteSchemas([]string{"Task"}))
+
+		field := newCRUDField("RestoreTask", "TaskRestorePayload")
+		assert.Equal(t, RestoreOperation, New().crudType(field))
+
+		rendered, err := plugin.mutationImplementer(field)
+		require.NoError(t, err)
+
+		assert.Contains(t, rendered, "Task.UpdateOneID(id).")
+		assert.Contains(t, rendered, "ClearDeletedAt().")
+		assert.Contains(t, rendered, "TaskRestorePayload{")
+		assert.NotContains(t, rendered, "SetNillableStatus")
+	})
+
+	t.Run("archivable schema with status argument", func(t *testing.T) {
+		plugin := NewWithOptions(WithSoftDeleteSchemas([]string{"Control"}), WithArchivableSchemas([]string{"Control"}))
+
+		field := newCRUDField("RestoreControl", "ControlRestorePayload")
+		field.FieldDefinition.Arguments = ast.ArgumentDefinitionList{
+			{Name: "id", Type: ast.NonNullNamedType("ID", nil)},
+			{Name: "status", Type: ast.NamedType("ControlStatus", nil)},
+		}
+
+		rendered, err := plugin.mutationImplementer(field)
+		require.NoError(t, err)
+
+		assert.Contains(t, rendered, "ClearDeletedAt().")
+		assert.Contains(t, rendered, "if res.Status == enums.ControlStatusArchived || status != nil {")
+		assert.Contains(t, rendered, "restoredStatus = *res.PreviousStatus")
+		assert.Contains(t, rendered, "restoredStatus = *status")
+		assert.Contains(t, rendered, "ClearPreviousStatus().")
+		assert.NotContains(t, rendered, "SetNillableStatus")
+	})
+
+	t.Run("schema without soft delete is not implemented", func(t *testing.T) {
+		plugin := New()
+
+		rendered, err := plugin.mutationImplementer(newCRUDField("RestoreTask", "TaskRestorePayload"))
+		require.NoError(t, err)
+
+		assert.Equal(t, fmt.Sprintf(defaultImplementation, "RestoreTask", "RestoreTask"), rendered)
+	})
+
+	t.Run("report", func(t *testing.T) {
+		plugin := NewWithOptions(WithSoftDeleteSchemas([]string{"Task"}))
+
+		entry := plugin.resolverReport(newCRUDField("RestoreTask", "TaskRestorePayload"))
+		assert.Equal(t, RestoreOperation, entry.Operation)
+		assert.Equal(t, "Task", entry.Entity)
+		assert.Equal(t, "restore.gotpl", entry.Template)
+
+		entry = New().resolverReport(newCRUDField("RestoreTask", "TaskRestorePayload"))
+		assert.Empty(t, entry.Operation)
+	})
+}
+
+func TestSoftDeleteThenRestoreStatus(t *testing.T) {
+	stubReserveImport(t)
+
+	plugin := NewWithOptions(
+		WithEntGeneratedPackage("github.com/example/ent/generated"),
+		WithSoftDeleteSchemas([]string{"Control"}),
+		WithArchivableSchemas([]string{"Control"}),
+	)
+
+	// the soft delete keeps the status the record had before it was archived
+	deleted, err := plugin.renderDelete(newCRUDField("DeleteControl", "ControlDeletePayload"))
+	require.NoError(t, err)
+
+	assert.Contains(t, deleted, "update.SetPreviousStatus(current.Status).SetStatus(enums.ControlStatusArchived)")
+
+	// restore and unarchive both bring that status back instead of the default status
+	testCases := []struct {
+		name       string
+		field      string
+		returnType string
+		record     string
+	}{
+		{
+			name:       "restore",
+			field:      "RestoreControl",
+			returnType: "ControlRestorePayload",
+			record:     "res",
+		},
+		{
+			name:       "unarchive",
+			field:      "UnarchiveControl",
+			returnType: "ControlUnarchivePayload",
+			record:     "current",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			field := newCRUDField(tc.field, tc.returnType)
+			field.FieldDefinition.Arguments = ast.ArgumentDefinitionList{{Name: "id", Type: ast.NonNullNamedType("ID", nil)}}
+
+			rendered, err := plugin.mutationImplementer(field)
+			require.NoError(t, err)
+
+			assert.Contains(t, rendered, "restoredStatus := control.DefaultStatus")
+			assert.Contains(t, rendered, fmt.Sprintf("if %s.PreviousStatus != nil {", tc.record))
+			assert.Contains(t, rendered, fmt.Sprintf("restoredStatus = *%s.PreviousStatus", tc.record))
+			assert.Contains(t, rendered, "SetStatus(restoredStatus).")
+			assert.Contains(t, rendered, "ClearPreviousStatus().")
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package resolvergen
+
+import (
+	"errors"
+	"io/fs"
+	"slices"
+)
+
+// templateRoot is the directory within the embedded filesystem that holds the default templates
+const templateRoot = "templates"
+
+// overlayFS is a read only filesystem that serves files from the override filesystem when they exist
+// and falls back to the base filesystem for everything else. This allows individual templates
+// to be replaced without copying all the embedded defaults
+type overlayFS struct {
+	// override is the user provided filesystem, checked first
+	override fs.FS
+	// base is the filesystem with the default templates
+	base fs.FS
+}
+
+var _ fs.GlobFS = (*overlayFS)(nil)
+
+// Open opens the named file from the override filesystem, falling back to the base filesystem
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.override.Open(name)
+	if err == nil {
+		return f, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return o.base.Open(name)
+}
+
+// Glob returns the names of all files matching the pattern in either filesystem
+func (o overlayFS) Glob(pattern string) ([]string, error) {
+	matches, err := fs.Glob(o.base, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := fs.Glob(o.override, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range overrides {
+		if !slices.Contains(matches, m) {
+			matches = append(matches, m)
+		}
+	}
+
+	slices.Sort(matches)
+
+	return matches, nil
+}
+
+// defaultTemplateFS returns the embedded default templates rooted at the templates directory
+func defaultTemplateFS() fs.FS {
+	// the root is a valid path within the embedded filesystem, so this never errors
+	sub, _ := fs.Sub(templates, templateRoot)
+
+	return sub
+}
+
+// templateFS returns the filesystem the templates are parsed from, overlaying the
+// user provided templates on top of the embedded defaults when configured
+func (r *ResolverPlugin) templateFS() fs.FS {
+	if r.templateOverrides == nil {
+		return defaultTemplateFS()
+	}
+
+	return overlayFS{
+		override: r.templateOverrides,
+		base:     defaultTemplateFS(),
+	}
+}
@@ -0,0 +1,102 @@
+package resolvergen
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlayFS(t *testing.T) {
+	override := fstest.MapFS{
+		"list.gotpl":               {Data: []byte("custom list")},
+		"updatefields/extra.gotpl": {Data: []byte("{{ define \"extra\" }}extra{{ end }}")},
+	}
+
+	fsys := overlayFS{
+		override: override,
+		base:     defaultTemplateFS(),
+	}
+
+	t.Run("override file is used", func(t *testing.T) {
+		content, err := fs.ReadFile(fsys, "list.gotpl")
+		require.NoError(t, err)
+		assert.Equal(t, "custom list", string(content))
+	})
+
+	t.Run("embedded file is used when not overridden", func(t *testing.T) {
+		content, err := fs.ReadFile(fsys, "create.gotpl")
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "Create().SetInput(input)")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := fs.ReadFile(fsys, "missing.gotpl")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("glob includes files from both filesystems", func(t *testing.T) {
+		matches, err := fs.Glob(fsys, "updatefields/*.gotpl")
+		require.NoError(t, err)
+		assert.Contains(t, matches, "updatefields/extra.gotpl")
+		assert.Contains(t, matches, "updatefields/updateresolver.gotpl")
+	})
+
+	t.Run("glob does not duplicate overridden files", func(t *testing.T) {
+		matches, err := fs.Glob(fsys, "*.gotpl")
+		require.NoError(t, err)
+
+		count := 0
+
+		for _, m := range matches {
+			if m == "list.gotpl" {
+				count++
+			}
+		}
+
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestTemplateFS(t *testing.T) {
+	t.Run("defaults to embedded templates", func(t *testing.T) {
+		plugin := New()
+
+		rendered, err := renderWorkflowTemplate(plugin.templateFS(), &workflowResolverTemplate{
+			HelperName: "workflowResolverHasPending",
+			ObjectType: "Control",
+			EntPackage: "generated",
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, rendered, "workflowResolverHasPending(ctx, generated.TypeControl, obj.ID)")
+	})
+
+	t.Run("override single template", func(t *testing.T) {
+		plugin := NewWithOptions(WithTemplateFS(fstest.MapFS{
+			"workflow.gotpl": {Data: []byte("return {{ .HelperName }}Custom(ctx, obj.ID)")},
+		}))
+
+		rendered, err := renderWorkflowTemplate(plugin.templateFS(), &workflowResolverTemplate{
+			HelperName: "workflowResolverHasPending",
+			ObjectType: "Control",
+			EntPackage: "generated",
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "return workflowResolverHasPendingCustom(ctx, obj.ID)", strings.TrimSpace(rendered))
+	})
+}
+
+func TestWithTemplateDir(t *testing.T) {
+	plugin := NewWithOptions(WithTemplateDir(t.TempDir()))
+	assert.NotNil(t, plugin.templateOverrides)
+
+	// a directory without templates falls back to the embedded templates
+	content, err := fs.ReadFile(plugin.templateFS(), "delete.gotpl")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "baseResolver")
+}
@@ -0,0 +1,69 @@
+package resolvergen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBulkUploadHeaderValidation(t *testing.T) {
+	stubReserveImport(t)
+
+	testCases := []struct {
+		name       string
+		field      string
+		returnType string
+		validator  string
+		unmarshal  string
+	}{
+		{
+			name:       "csv bulk create",
+			field:      "CreateBulkCSVControl",
+			returnType: "ControlBulkCreatePayload",
+			validator:  "r.validateControlCSVHeader(input.File)",
+			unmarshal:  "common.UnmarshalBulkData[csvgenerated.ControlCSVInput](input)",
+		},
+		{
+			name:       "csv bulk update",
+			field:      "UpdateBulkCSVControl",
+			returnType: "ControlBulkUpdatePayload",
+			validator:  "r.validateControlCSVUpdateHeader(input.File)",
+			unmarshal:  "common.UnmarshalBulkData[csvgenerated.ControlCSVUpdateInput](input)",
+		},
+		{
+			name:       "csv bulk upsert",
+			field:      "CreateOrUpdateBulkCSVControl",
+			returnType: "ControlBulkUpsertPayload",
+			validator:  "r.validateControlCSVUpsertHeader(input.File)",
+			unmarshal:  "common.UnmarshalBulkData[ControlCSVUpsertInput](input)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(WithCSVGeneratedPackage("github.com/example/app/internal/ent/csvgenerated"))
+
+			rendered, err := plugin.renderBulkUpload(newCRUDField(tc.field, tc.returnType))
+			require.NoError(t, err)
+
+			// the header is validated before the file is read by the unmarshal
+			require.Contains(t, rendered, tc.validator)
+			require.Contains(t, rendered, tc.unmarshal)
+			assert.Less(t, strings.Index(rendered, tc.validator), strings.Index(rendered, tc.unmarshal))
+
+			// the file without the required column markers is unmarshalled
+			assert.Contains(t, rendered, "file, err := "+tc.validator)
+			assert.Contains(t, rendered, "input.File = file")
+		})
+	}
+
+	t.Run("upload without csv generated package", func(t *testing.T) {
+		rendered, err := NewWithOptions().renderBulkUpload(newCRUDField("CreateBulkCSVControl", "ControlBulkCreatePayload"))
+		require.NoError(t, err)
+
+		assert.NotContains(t, rendered, "validateControlCSVHeader")
+		assert.Contains(t, rendered, "file, err := graphutils.TrimCSVHeaderMarkers(input.File)")
+	})
+}
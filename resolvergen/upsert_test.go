@@ -0,0 +1,62 @@
+package resolvergen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBulkUpsert(t *testing.T) {
+	stubReserveImport(t)
+
+	testCases := []struct {
+		name        string
+		field       string
+		opts        []Options
+		render      func(*ResolverPlugin, string) (string, error)
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:  "bulk upsert",
+			field: "UpsertBulkControl",
+			render: func(r *ResolverPlugin, field string) (string, error) {
+				return r.renderBulk(newCRUDField(field, "ControlBulkUpsertPayload"))
+			},
+			contains:    []string{"return r.bulkUpsertControl(ctx, graphutils.NewUpsertRows(input))"},
+			notContains: []string{"bulkCreateControl", "bulkUpdateControl"},
+		},
+		{
+			name:  "csv bulk create or update",
+			field: "CreateOrUpdateBulkCSVControl",
+			opts:  []Options{WithCSVGeneratedPackage("github.com/example/app/internal/ent/csvgenerated")},
+			render: func(r *ResolverPlugin, field string) (string, error) {
+				return r.renderBulkUpload(newCRUDField(field, "ControlBulkUpsertPayload"))
+			},
+			contains: []string{
+				"common.UnmarshalBulkData[ControlCSVUpsertInput](input)",
+				"ID: data[i].ID, Input: &data[i].Input",
+				"return r.bulkUpsertControl(ctx, rows)",
+			},
+			notContains: []string{"bulkUpdateCSVControl", "bulkCreateControl"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin := NewWithOptions(tc.opts...)
+
+			rendered, err := tc.render(plugin, tc.field)
+			require.NoError(t, err)
+
+			for _, s := range tc.contains {
+				assert.Contains(t, rendered, s)
+			}
+
+			for _, s := range tc.notContains {
+				assert.NotContains(t, rendered, s)
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 // workflowResolverHelperFile is the filename for the generated workflow resolver helper functions
@@ -35,9 +37,27 @@ type workflowResolverUpdate struct {
 	graphCommonImportPath string
 }
 
+// workflowImportPaths holds the configured import paths used by the workflow resolver helpers,
+// empty paths are detected from the resolver files or derived from the module root
+type workflowImportPaths struct {
+	// generated is the import path for the ent generated package
+	generated string
+	// graphCommon is the import path for the graphapi common package
+	graphCommon string
+	// enums is the import path for the enums package with the workflow enums
+	enums string
+	// workflows is the import path for the workflows package with the workflow filters
+	workflows string
+}
+
 // UpdateWorkflowResolvers generates shared workflow resolver helper implementations
 // when workflow fields are present in resolver files.
 func UpdateWorkflowResolvers(graphResolverDir string) error {
+	return updateWorkflowResolvers(graphResolverDir, workflowImportPaths{})
+}
+
+// updateWorkflowResolvers generates the workflow resolver helpers using the configured import paths
+func updateWorkflowResolvers(graphResolverDir string, paths workflowImportPaths) error {
 	if graphResolverDir == "" {
 		return ErrGraphResolverDirRequired
 	}
@@ -50,8 +70,8 @@ func UpdateWorkflowResolvers(graphResolverDir string) error {
 	var (
 		foundWorkflow         bool
 		packageName           string
-		generatedImportPath   string
-		graphCommonImportPath string
+		generatedImportPath   = paths.generated
+		graphCommonImportPath = paths.graphCommon
 	)
 
 	for _, path := range resolverFiles {
@@ -81,25 +101,36 @@ func UpdateWorkflowResolvers(graphResolverDir string) error {
 		return nil
 	}
 
-	moduleRoot := moduleRootFromGenerated(generatedImportPath)
-	if moduleRoot == "" {
-		moduleRoot = moduleRootFromGoMod(graphResolverDir)
-	}
+	enumsImportPath := paths.enums
+	workflowsImportPath := paths.workflows
 
-	if moduleRoot == "" {
-		return ErrModuleRootNotFound
-	}
+	// the module root is only needed to derive the import paths that were not configured or detected
+	if generatedImportPath == "" || graphCommonImportPath == "" || enumsImportPath == "" || workflowsImportPath == "" {
+		moduleRoot := moduleRootFromGenerated(generatedImportPath)
+		if moduleRoot == "" {
+			moduleRoot = moduleRootFromGoMod(graphResolverDir)
+		}
 
-	if generatedImportPath == "" {
-		generatedImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "internal/ent/generated"))
-	}
+		if moduleRoot == "" {
+			return ErrModuleRootNotFound
+		}
 
-	if graphCommonImportPath == "" {
-		graphCommonImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "internal/graphapi/common"))
-	}
+		if generatedImportPath == "" {
+			generatedImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "internal/ent/generated"))
+		}
+
+		if graphCommonImportPath == "" {
+			graphCommonImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "internal/graphapi/common"))
+		}
 
-	enumsImportPath := filepath.ToSlash(filepath.Join(moduleRoot, "common/enums"))
-	workflowsImportPath := filepath.ToSlash(filepath.Join(moduleRoot, "internal/workflows"))
+		if enumsImportPath == "" {
+			enumsImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "common/enums"))
+		}
+
+		if workflowsImportPath == "" {
+			workflowsImportPath = filepath.ToSlash(filepath.Join(moduleRoot, "internal/workflows"))
+		}
+	}
 
 	if packageName == "" {
 		packageName = "graphapi"
@@ -238,14 +269,14 @@ import (
 
 	"entgo.io/contrib/entgql"
 	"entgo.io/ent/dialect/sql"
+	%s
+	%s
+	%s
 	"%s"
 	"%s"
 	"%s"
 	"%s"
-	"%s"
-	"%s"
-	"%s"
-	"%s"
+	%s
 )
 
 // workflowResolverHasPending checks if the object has any pending workflow proposals (draft or submitted).
@@ -450,7 +481,12 @@ func workflowTimelineEventTypes(includeEmitFailures bool) []enums.WorkflowEventT
 
 	return eventTypes
 }
-`, packageName, enumsImportPath, generatedImportPath, graphCommonImportPath, workflowEventImportPath, workflowInstanceImportPath, workflowObjectRefImportPath, workflowProposalImportPath, workflowsImportPath)
+`, packageName,
+		runtimeimports.ImportLine(enumsImportPath, runtimeimports.EnumsAlias),
+		runtimeimports.ImportLine(generatedImportPath, "generated"),
+		runtimeimports.ImportLine(graphCommonImportPath, "common"),
+		workflowEventImportPath, workflowInstanceImportPath, workflowObjectRefImportPath, workflowProposalImportPath,
+		runtimeimports.ImportLine(workflowsImportPath, runtimeimports.WorkflowsAlias))
 
 	formatted, err := format.Source([]byte(content))
 	if err != nil {
@@ -104,26 +104,84 @@ func (r *controlResolver) WorkflowTimeline(ctx context.Context, obj *generated.C
 	}
 }
 
+func TestUpdateWorkflowResolversWithImportPaths(t *testing.T) {
+	t.Parallel()
+
+	// no go.mod and no generated import in the resolver file, so all paths must come from the config
+	graphDir := t.TempDir()
+
+	source := `package resolvers
+
+import (
+	"context"
+)
+
+func (r *controlResolver) HasPendingWorkflow(ctx context.Context, obj *ent.Control) (bool, error) {
+	return workflowResolverHasPending(ctx, ent.TypeControl, obj.ID)
+}
+`
+
+	if err := os.WriteFile(filepath.Join(graphDir, "control.resolvers.go"), []byte(source), 0o600); err != nil { // nolint:mnd
+		t.Fatalf("write resolver file: %v", err)
+	}
+
+	err := updateWorkflowResolvers(graphDir, workflowImportPaths{
+		generated:   "example.com/app/ent",
+		graphCommon: "example.com/app/graph/common",
+		enums:       "example.com/app/types",
+		workflows:   "example.com/app/flows",
+	})
+	if err != nil {
+		t.Fatalf("updateWorkflowResolvers failed: %v", err)
+	}
+
+	helperBytes, err := os.ReadFile(filepath.Join(graphDir, workflowResolverHelperFile))
+	if err != nil {
+		t.Fatalf("read helper file: %v", err)
+	}
+
+	helperStr := string(helperBytes)
+
+	for _, expected := range []string{
+		"package resolvers",
+		`generated "example.com/app/ent"`,
+		`"example.com/app/graph/common"`,
+		`enums "example.com/app/types"`,
+		`workflows "example.com/app/flows"`,
+		`"example.com/app/ent/workflowinstance"`,
+	} {
+		if !strings.Contains(helperStr, expected) {
+			t.Fatalf("expected helper file to contain %s", expected)
+		}
+	}
+}
+
 func TestRenderWorkflowTemplate(t *testing.T) {
 	t.Parallel()
 
-	rendered := renderWorkflowTemplate(&workflowResolverTemplate{
+	rendered, err := renderWorkflowTemplate(defaultTemplateFS(), &workflowResolverTemplate{
 		HelperName: "workflowResolverHasPending",
 		ObjectType: "Control",
 		EntPackage: "generated",
 	})
+	if err != nil {
+		t.Fatalf("render workflow template: %v", err)
+	}
 
 	normalized := normalizeWhitespace(rendered)
 	if !strings.Contains(normalized, "return workflowResolverHasPending(ctx, generated.TypeControl, obj.ID)") {
 		t.Fatalf("expected template to render helper call")
 	}
 
-	renderedTimeline := renderWorkflowTemplate(&workflowResolverTemplate{
+	renderedTimeline, err := renderWorkflowTemplate(defaultTemplateFS(), &workflowResolverTemplate{
 		HelperName: "workflowResolverTimeline",
 		ObjectType: "Control",
 		EntPackage: "generated",
 		IsTimeline: true,
 	})
+	if err != nil {
+		t.Fatalf("render workflow timeline template: %v", err)
+	}
 
 	normalizedTimeline := normalizeWhitespace(renderedTimeline)
 	timelineCall := "return workflowResolverTimeline(ctx, generated.TypeControl, obj.ID, after, first, before, last, orderBy, where, includeEmitFailures"
@@ -3,9 +3,13 @@ package resolvergen
 import (
 	"bytes"
 	"html/template"
+	"io/fs"
 	"strings"
 )
 
+// workflowTemplate is the name of the template used for the workflow resolver fields
+const workflowTemplate = "workflow.gotpl"
+
 type workflowResolverTemplate struct {
 	HelperName string
 	ObjectType string
@@ -13,16 +17,17 @@ type workflowResolverTemplate struct {
 	IsTimeline bool
 }
 
-func renderWorkflowTemplate(input *workflowResolverTemplate) string {
-	t, err := template.New("workflow.gotpl").ParseFS(templates, "templates/workflow.gotpl")
+// renderWorkflowTemplate renders the workflow resolver template from the template filesystem
+func renderWorkflowTemplate(fsys fs.FS, input *workflowResolverTemplate) (string, error) {
+	t, err := template.New(workflowTemplate).ParseFS(fsys, workflowTemplate)
 	if err != nil {
-		panic(err)
+		return "", err
 	}
 
 	var code bytes.Buffer
 	if err = t.Execute(&code, input); err != nil {
-		panic(err)
+		return "", err
 	}
 
-	return strings.Trim(code.String(), "\t \n")
+	return strings.Trim(code.String(), "\t \n"), nil
 }
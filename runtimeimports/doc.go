@@ -0,0 +1,2 @@
+// Package runtimeimports provides the configurable runtime packages referenced by the code generated by the plugins
+package runtimeimports
@@ -0,0 +1,92 @@
+package runtimeimports
+
+import (
+	"strings"
+)
+
+const (
+	// DefaultLogger is the default package providing FromContext(ctx) for logging
+	DefaultLogger = "github.com/theopenlane/core/pkg/logx"
+	// DefaultErrors is the default package providing the request error helpers, e.g. NewMissingRequiredFieldError
+	DefaultErrors = "github.com/theopenlane/utils/rout"
+	// DefaultGraphErrors is the default package providing the graph error messages, e.g. BulkActionIncomplete
+	DefaultGraphErrors = "github.com/theopenlane/core/internal/graphapi/gqlerrors"
+	// DefaultEnums is the default package providing the enums, e.g. the archived status of a schema
+	DefaultEnums = "github.com/theopenlane/core/pkg/enums"
+	// DefaultAuthz is the default package providing the authorization relations, e.g. CanEdit and CanDelete
+	DefaultAuthz = "github.com/theopenlane/iam/fgax"
+	// DefaultAuth is the default package providing the authentication helpers, e.g. IsSystemAdminFromContext
+	DefaultAuth = "github.com/theopenlane/iam/auth"
+)
+
+const (
+	// LoggerAlias is the name the logger package is referenced by in the generated code
+	LoggerAlias = "logx"
+	// ErrorsAlias is the name the errors package is referenced by in the generated code
+	ErrorsAlias = "rout"
+	// GraphErrorsAlias is the name the graph errors package is referenced by in the generated code
+	GraphErrorsAlias = "gqlerrors"
+	// EnumsAlias is the name the enums package is referenced by in the generated code
+	EnumsAlias = "enums"
+	// AuthzAlias is the name the authz package is referenced by in the generated code
+	AuthzAlias = "fgax"
+	// AuthAlias is the name the auth package is referenced by in the generated code
+	AuthAlias = "auth"
+	// WorkflowsAlias is the name the workflows package is referenced by in the generated code
+	WorkflowsAlias = "workflows"
+)
+
+// Config holds the import paths of the runtime packages the generated code depends on.
+// The generated code references each package by a fixed alias (e.g. logx, rout), so a
+// replacement package must provide the same functions used by the templates.
+// Empty paths use the defaults of the openlane core layout
+type Config struct {
+	// Logger is the package providing FromContext(ctx) returning a zerolog compatible logger
+	Logger string
+	// Errors is the package providing the request error helpers, e.g. NewMissingRequiredFieldError and ErrPermissionDenied
+	Errors string
+	// GraphErrors is the package providing the graph error messages, e.g. BulkActionIncomplete
+	GraphErrors string
+	// Enums is the package providing the enums used by the generated code, e.g. <Schema>StatusArchived
+	// for resolvers and the workflow enums for the workflow resolver helpers
+	Enums string
+	// Authz is the package providing the authorization relations, e.g. CanEdit and CanDelete
+	Authz string
+	// Auth is the package providing the authentication helpers, e.g. IsSystemAdminFromContext
+	Auth string
+	// Workflows is the package providing the workflow filters used by the workflow resolver helpers,
+	// defaults to internal/workflows within the module
+	Workflows string
+}
+
+// WithDefaults returns a copy of the config with the empty import paths set to the defaults
+func (c Config) WithDefaults() Config {
+	c.Logger = withDefault(c.Logger, DefaultLogger)
+	c.Errors = withDefault(c.Errors, DefaultErrors)
+	c.GraphErrors = withDefault(c.GraphErrors, DefaultGraphErrors)
+	c.Enums = withDefault(c.Enums, DefaultEnums)
+	c.Authz = withDefault(c.Authz, DefaultAuthz)
+	c.Auth = withDefault(c.Auth, DefaultAuth)
+
+	return c
+}
+
+// ImportLine returns the import spec for the path, adding the alias when the last element
+// of the path does not match the name the generated code references the package by
+func ImportLine(path, alias string) string {
+	parts := strings.Split(path, "/")
+	if parts[len(parts)-1] == alias {
+		return `"` + path + `"`
+	}
+
+	return alias + ` "` + path + `"`
+}
+
+// withDefault returns the value or the default when the value is empty
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
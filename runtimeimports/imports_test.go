@@ -0,0 +1,66 @@
+package runtimeimports
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDefaults(t *testing.T) {
+	t.Run("empty config uses defaults", func(t *testing.T) {
+		cfg := Config{}.WithDefaults()
+
+		assert.Equal(t, DefaultLogger, cfg.Logger)
+		assert.Equal(t, DefaultErrors, cfg.Errors)
+		assert.Equal(t, DefaultGraphErrors, cfg.GraphErrors)
+		assert.Equal(t, DefaultEnums, cfg.Enums)
+		assert.Equal(t, DefaultAuthz, cfg.Authz)
+		assert.Equal(t, DefaultAuth, cfg.Auth)
+		assert.Empty(t, cfg.Workflows)
+	})
+
+	t.Run("configured paths are kept", func(t *testing.T) {
+		cfg := Config{
+			Logger: "github.com/example/app/pkg/log",
+			Authz:  "github.com/example/app/pkg/authz",
+		}.WithDefaults()
+
+		assert.Equal(t, "github.com/example/app/pkg/log", cfg.Logger)
+		assert.Equal(t, "github.com/example/app/pkg/authz", cfg.Authz)
+		assert.Equal(t, DefaultErrors, cfg.Errors)
+	})
+}
+
+func TestImportLine(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		alias    string
+		expected string
+	}{
+		{
+			name:     "package name matches alias",
+			path:     "github.com/theopenlane/core/pkg/logx",
+			alias:    LoggerAlias,
+			expected: `"github.com/theopenlane/core/pkg/logx"`,
+		},
+		{
+			name:     "package name differs from alias",
+			path:     "github.com/example/app/pkg/log",
+			alias:    LoggerAlias,
+			expected: `logx "github.com/example/app/pkg/log"`,
+		},
+		{
+			name:     "single element path",
+			path:     "enums",
+			alias:    EnumsAlias,
+			expected: `"enums"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ImportLine(tc.path, tc.alias))
+		})
+	}
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/gertd/go-pluralize"
 	"github.com/stoewer/go-strcase"
 	"github.com/theopenlane/entx/genhooks"
+
+	"github.com/theopenlane/gqlgen-plugins/genreport"
+	"github.com/theopenlane/gqlgen-plugins/runtimeimports"
 )
 
 const (
@@ -49,6 +52,10 @@ type SearchPlugin struct {
 	idFields []string
 	// includeAdminSearch indicates whether to generate the admin search resolver
 	includeAdminSearch bool
+	// runtimeImports are the import paths of the runtime packages referenced by the generated code
+	runtimeImports runtimeimports.Config
+	// dryRunReport when set records the searchable objects instead of generating the search resolvers
+	dryRunReport *genreport.Report
 }
 
 // Name returns the name of the plugin
@@ -131,6 +138,22 @@ func WithSchemaPath(path string) Options {
 	}
 }
 
+// WithRuntimeImports sets the import paths of the runtime packages referenced by the generated code,
+// such as the logger and auth package, empty paths use the defaults of the openlane core layout
+func WithRuntimeImports(imports runtimeimports.Config) Options {
+	return func(p *SearchPlugin) {
+		p.runtimeImports = imports
+	}
+}
+
+// WithDryRun records the searchable objects and fields in the report instead of
+// generating the search resolvers, no files are written
+func WithDryRun(report *genreport.Report) Options {
+	return func(p *SearchPlugin) {
+		p.dryRunReport = report
+	}
+}
+
 // SearchResolverBuild is a struct to hold the objects for the bulk resolver
 type SearchResolverBuild struct {
 	// Name of the search type
@@ -151,6 +174,8 @@ type SearchResolverBuild struct {
 	IDFields []string
 	// IncludeAdminSearch indicates whether the admin search helpers should be generated
 	IncludeAdminSearch bool
+	// Imports are the import paths of the runtime packages referenced by the templates
+	Imports runtimeimports.Config
 }
 
 // Object is a struct to hold the object name for the bulk resolver
@@ -170,6 +195,18 @@ func (r SearchPlugin) GenerateCode(data *codegen.Data) error {
 		return err
 	}
 
+	if r.dryRunReport != nil {
+		for _, o := range inputData.Objects {
+			r.dryRunReport.AddSearchObject(genreport.SearchObject{
+				Object:      o.Name,
+				Fields:      searchFieldNames(o.Fields),
+				AdminFields: searchFieldNames(o.AdminFields),
+			})
+		}
+
+		return nil
+	}
+
 	inputData.ModelImport = r.modelPackage
 
 	// only add the model package if the import is not empty
@@ -186,6 +223,7 @@ func (r SearchPlugin) GenerateCode(data *codegen.Data) error {
 	inputData.EntImport = r.entGeneratedPackage
 	inputData.RuleImport = r.rulePackage
 	inputData.GraphQLImport = r.graphqlImport
+	inputData.Imports = r.runtimeImports.WithDefaults()
 
 	// set the default ID fields
 	inputData.IDFields = defaultIDFields
@@ -286,6 +324,17 @@ func genSearchResolver(data *codegen.Data, inputData SearchResolverBuild, resolv
 	})
 }
 
+// searchFieldNames returns the names of the search fields
+func searchFieldNames(fields []genhooks.Field) []string {
+	names := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
+
 // isIDField checks if the field is an ID field
 func isIDField(f string, idFields []string) bool {
 	for _, idField := range idFields {